@@ -1,14 +1,21 @@
-package main
+package gobridge
 
 import (
 	"errors"
+	"math"
+	"reflect"
+	"regexp"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
 )
 
 var NoJsType = errors.New("Cannot find corresponding JS type")
 
+// Fixed arrays longer than this are not worth spelling out as a tuple.
+const MAX_TUPLE_LENGTH = 8
+
 func getJsType(goType string) (string, error) {
 	switch goType {
 	case "int":
@@ -44,6 +51,49 @@ func getJsType(goType string) (string, error) {
 	return "", NoJsType
 }
 
+func isIntegerType(goType string) bool {
+	switch goType {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return true
+	}
+
+	return false
+}
+
+func isUnsignedType(goType string) bool {
+	return isIntegerType(goType) && goType[0] == 'u'
+}
+
+// encoding/json writes []byte as a base64 string, but [N]byte as numbers.
+func isByteSlice(field ArrayStructField) bool {
+	element, isBasic := field.Type.(BasicStructField)
+	return isBasic && element.Type == "uint8" && !field.Fixed
+}
+
+func isInt64Type(goType string) bool {
+	return goType == "int64" || goType == "uint64"
+}
+
+// Returns the min and max values of integer types that fit in a JS number.
+func getIntegerRange(goType string) (int64, int64, bool) {
+	switch goType {
+	case "int8":
+		return math.MinInt8, math.MaxInt8, true
+	case "int16":
+		return math.MinInt16, math.MaxInt16, true
+	case "int32":
+		return math.MinInt32, math.MaxInt32, true
+	case "uint8":
+		return 0, math.MaxUint8, true
+	case "uint16":
+		return 0, math.MaxUint16, true
+	case "uint32":
+		return 0, math.MaxUint32, true
+	}
+
+	return 0, 0, false
+}
+
 func getSpaces(indent uint) string {
 	output := ""
 	var i uint = 0
@@ -61,33 +111,65 @@ func getSpaces(indent uint) string {
 // the resultant code.
 // ==================================================
 
-func recGetLastType(field StructField) string {
+// Returns the namespaced names of every struct a field depends on.
+func recGetDependencies(field StructField) []string {
 	switch t := field.(type) {
 	case BasicStructField:
-		return t.Type
+		_, err := getJsType(t.Type)
+		if err != NoJsType {
+			return []string{}
+		}
+
+		return []string{t.Type}
+	case UnknownStructField:
+		return []string{}
 	case MapStructField:
-		return recGetLastType(t.Value)
+		return recGetDependencies(t.Value)
 	case ArrayStructField:
-		return recGetLastType(t.Type)
+		return recGetDependencies(t.Type)
 	case AnonStructField:
-		return recGetLastType(t.Fields[0])
+		dependencies := make([]string, 0)
+		for _, f := range t.Fields {
+			dependencies = append(dependencies, recGetDependencies(f)...)
+		}
+
+		return dependencies
+	case UnionStructField:
+		dependencies := make([]string, 0, len(t.Variants))
+		for _, variant := range t.Variants {
+			dependencies = append(dependencies, variant.Type)
+		}
+
+		return dependencies
 	default:
 		panic("Switch should be exhaustive")
 	}
 }
 
+// Structs in a cycle use a struct that is declared after them,
+// which they can only read once it is, from a lazy schema.
+func usesUndefinedStruct(field StructField, defined map[string]bool) bool {
+	for _, dependency := range recGetDependencies(field) {
+		if !defined[dependency] {
+			return true
+		}
+	}
+
+	return false
+}
+
 /*
  * Returned a topologically ordered list of structs,
  * This function is ugly and quite inefficient,
  * much room for improvement.
  */
-func orderStructList(structList StructList) (StructList, error) {
+func OrderStructs(structList StructList) (StructList, error) {
 	//
 	// Because we used a map while getting our structs,
 	// We need to order the structs based on order they came to us.
 	// So we can get deterministic output.
 	//
-	sort.Slice(structList, func(i, j int) bool {
+	sort.SliceStable(structList, func(i, j int) bool {
 		return structList[i].Order < structList[j].Order
 	})
 
@@ -106,24 +188,17 @@ func orderStructList(structList StructList) (StructList, error) {
 
 	for i, node := range nodeMap {
 		for _, field := range structList[i].Fields {
-			_, isUnknown := field.(UnknownStructField)
-			if isUnknown {
-				continue
-			}
-
-			lastType := recGetLastType(field)
+			for _, dependency := range recGetDependencies(field) {
+				nodeIndex := slices.IndexFunc(nodeMap, func(n *Node) bool {
+					return n.Name == dependency
+				})
 
-			_, err := getJsType(lastType)
+				if nodeIndex == -1 {
+					return structList, errors.New("Could not find struct " + dependency)
+				}
 
-			if err != NoJsType {
-				continue
+				node.Edges = append(node.Edges, nodeMap[nodeIndex])
 			}
-
-			nodeIndex := slices.IndexFunc(nodeMap, func(n *Node) bool {
-				return n.Name == lastType
-			})
-
-			node.Edges = append(node.Edges, nodeMap[nodeIndex])
 		}
 
 		nodeList = append(nodeList, node)
@@ -185,7 +260,7 @@ func appendedType(t string) string {
 	return t + "()),\n"
 }
 
-func getStructFieldType(validators map[string]uint, nameMap map[string]string, counter *uint, field StructField, indent uint) (string, error) {
+func getStructFieldType(validators map[string]uint, nameMap map[string]string, counter *uint, field StructField, indent uint, options Options) (string, error) {
 	switch t := field.(type) {
 	case BasicStructField:
 		jsType, err := getJsType(t.Type)
@@ -194,31 +269,119 @@ func getStructFieldType(validators map[string]uint, nameMap map[string]string, c
 			return nameMap[t.Type], nil
 		}
 
+		if hasJsonOption(t.StructTag, "string") {
+			return getQuotedValidator(validators, counter, t.Type, options), nil
+		}
+
+		if jsType == "number" {
+			return getNumberValidator(validators, counter, t.Type, options), nil
+		}
+
 		maybeAdd(validators, counter, jsType)
 		return jsType + "()", nil
 	case UnknownStructField:
+		override, hasOverride := options.Overrides[t.FullType]
+		if hasOverride {
+			for _, validator := range getValidatorCalls(override) {
+				maybeAdd(validators, counter, validator)
+			}
+
+			return override, nil
+		}
+
+		switch t.Encoding {
+		case MARSHAL_JSON:
+			options.warn(t.FullType + " implements json.Marshaler, using unknown() as we can't know its schema. Add an override to describe it.")
+			maybeAdd(validators, counter, "unknown")
+			return "unknown()", nil
+		case MARSHAL_TEXT:
+			options.warn(t.FullType + " implements encoding.TextMarshaler, using string() as we can't know its format. Add an override to describe it.")
+			maybeAdd(validators, counter, "string")
+			return "string()", nil
+		}
+
+		// Interfaces could be anything, unlike types we couldn't resolve.
+		if t.FullType == ANY_TYPE {
+			maybeAdd(validators, counter, "unknown")
+			return "unknown()", nil
+		}
+
 		maybeAdd(validators, counter, "any")
 		return "any()", nil
+	case UnionStructField:
+		maybeAdd(validators, counter, "variant")
+		maybeAdd(validators, counter, "literal")
+
+		discriminatorKey := getObjectKey(BasicStructField{FieldName: t.Discriminator})
+
+		variants := make([]string, 0, len(t.Variants))
+		for _, variant := range t.Variants {
+			variants = append(variants, "object({ ..."+nameMap[variant.Type]+".entries, "+discriminatorKey+": literal("+strconv.Quote(variant.Value)+") })")
+		}
+
+		return "variant(" + strconv.Quote(t.Discriminator) + ", [" + strings.Join(variants, ", ") + "])", nil
 	case ArrayStructField:
+		if isByteSlice(t) {
+			maybeAdd(validators, counter, "pipe")
+			maybeAdd(validators, counter, "string")
+			maybeAdd(validators, counter, "base64")
+
+			return "pipe(string(), base64())", nil
+		}
+
+		if t.Fixed && t.Length <= MAX_TUPLE_LENGTH {
+			maybeAdd(validators, counter, "tuple")
+			recValue, err := getStructFieldType(validators, nameMap, counter, t.Type, indent+1, options)
+			if err != nil {
+				return "", err
+			}
+
+			return "tuple([" + strings.TrimSuffix(strings.Repeat(recValue+", ", t.Length), ", ") + "])", nil
+		}
+
+		if t.Fixed {
+			maybeAdd(validators, counter, "pipe")
+		}
+
 		maybeAdd(validators, counter, "array")
-		recValue, err := getStructFieldType(validators, nameMap, counter, t.Type, indent+1)
+		recValue, err := getStructFieldType(validators, nameMap, counter, t.Type, indent+1, options)
 		if err != nil {
 			return "", err
 		}
 
+		if t.Fixed {
+			maybeAdd(validators, counter, "length")
+			return "pipe(array(" + recValue + "), length(" + strconv.Itoa(t.Length) + "))", nil
+		}
+
 		return "array(" + recValue + ")", nil
 	case MapStructField:
 		maybeAdd(validators, counter, "record")
-		recValue, err := getStructFieldType(validators, nameMap, counter, t.Value, indent+1)
+
+		//
+		// encoding/json writes integer keys as their decimal string.
+		//
+		keyValue := "string(), "
+		if isIntegerType(t.KeyType) {
+			maybeAdd(validators, counter, "pipe")
+			maybeAdd(validators, counter, "string")
+			maybeAdd(validators, counter, "regex")
+
+			keyValue = "pipe(string(), regex(" + getNumberRegex(t.KeyType) + ")), "
+		} else {
+			maybeAdd(validators, counter, "string")
+		}
+
+		recValue, err := getStructFieldType(validators, nameMap, counter, t.Value, indent+1, options)
 		if err != nil {
 			return "", err
 		}
 
-		return "record(" + recValue + ")", nil
+		return "record(" + keyValue + recValue + ")", nil
 	case AnonStructField:
 		output := "object({\n"
 		for _, v := range t.Fields {
-			fieldOutput, err := getSingleField(validators, nameMap, counter, v, indent+1)
+			fieldOutput, err := getSingleField(validators, nameMap, counter, v, indent+1, options)
 			if err != nil {
 				return "", err
 			}
@@ -232,13 +395,158 @@ func getStructFieldType(validators map[string]uint, nameMap map[string]string, c
 	}
 }
 
-func getSingleField(validators map[string]uint, nameMap map[string]string, counter *uint, field StructField, indent uint) (string, error) {
-	typeValue, err := getStructFieldType(validators, nameMap, counter, field, indent)
+func getSingleField(validators map[string]uint, nameMap map[string]string, counter *uint, field StructField, indent uint, options Options) (string, error) {
+	typeValue, err := getStructFieldType(validators, nameMap, counter, field, indent, options)
 	if err != nil {
 		return "", err
 	}
 
-	return getSpaces(indent+1) + field.Name() + ": " + typeValue + ",\n", nil
+	return getSpaces(indent+1) + getObjectKey(field) + ": " + typeValue + ",\n", nil
+}
+
+var jsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// Finds the validators an override schema calls, so we can import them.
+var validatorCall = regexp.MustCompile(`[A-Za-z_$][\w$]*\(`)
+
+func getValidatorCalls(schema string) []string {
+	calls := make([]string, 0)
+
+	for _, match := range validatorCall.FindAllStringIndex(schema, -1) {
+		// Methods, or the middle of a longer name.
+		if match[0] > 0 && strings.ContainsAny(schema[match[0]-1:match[0]], ".$_0123456789") {
+			continue
+		}
+
+		name := schema[match[0] : match[1]-1]
+		if !isValibotExport(name) {
+			continue
+		}
+
+		calls = append(calls, name)
+	}
+
+	return calls
+}
+
+// The key of a field, as encoding/json would write it.
+func getObjectKey(field StructField) string {
+	jsonName, _ := getJsonTagName(field.Tag())
+	if jsonName == "" {
+		jsonName = field.Name()
+	}
+
+	if !jsIdentifier.MatchString(jsonName) {
+		return strconv.Quote(jsonName)
+	}
+
+	return jsonName
+}
+
+func hasJsonOption(tag string, option string) bool {
+	jsonTag := reflect.StructTag(tag).Get("json")
+
+	return slices.Contains(strings.Split(jsonTag, ",")[1:], option)
+}
+
+// The integer() and range checks for a golang integer type, to be used inside a pipe.
+func getIntegerChecks(validators map[string]uint, counter *uint, goType string) []string {
+	if !isIntegerType(goType) {
+		return []string{}
+	}
+
+	maybeAdd(validators, counter, "integer")
+	checks := []string{"integer()"}
+
+	min, max, hasRange := getIntegerRange(goType)
+	if !hasRange && isUnsignedType(goType) {
+		maybeAdd(validators, counter, "minValue")
+		checks = append(checks, "minValue(0)")
+	}
+
+	if hasRange {
+		maybeAdd(validators, counter, "minValue")
+		maybeAdd(validators, counter, "maxValue")
+		checks = append(checks, "minValue("+strconv.FormatInt(min, 10)+")", "maxValue("+strconv.FormatInt(max, 10)+")")
+	}
+
+	return checks
+}
+
+func getNumberValidator(validators map[string]uint, counter *uint, goType string, options Options) string {
+	if isInt64Type(goType) && options.Int64 == Int64AsBigInt {
+		maybeAdd(validators, counter, "bigint")
+		return "bigint()"
+	}
+
+	if isInt64Type(goType) && options.Int64 == Int64AsString {
+		maybeAdd(validators, counter, "pipe")
+		maybeAdd(validators, counter, "string")
+		maybeAdd(validators, counter, "regex")
+		return "pipe(string(), regex(" + getNumberRegex(goType) + "))"
+	}
+
+	if !options.IntegerChecks || !isIntegerType(goType) {
+		maybeAdd(validators, counter, "number")
+		return "number()"
+	}
+
+	maybeAdd(validators, counter, "pipe")
+	maybeAdd(validators, counter, "number")
+	checks := getIntegerChecks(validators, counter, goType)
+
+	return "pipe(number(), " + strings.Join(checks, ", ") + ")"
+}
+
+func getNumberRegex(goType string) string {
+	if isUnsignedType(goType) {
+		return `/^\d+$/`
+	}
+
+	if isIntegerType(goType) {
+		return `/^-?\d+$/`
+	}
+
+	return `/^-?\d+(\.\d+)?([eE][+-]?\d+)?$/`
+}
+
+// Fields tagged with `json:",string"` are encoded by encoding/json as a JSON string.
+func getQuotedValidator(validators map[string]uint, counter *uint, goType string, options Options) string {
+	maybeAdd(validators, counter, "pipe")
+
+	switch goType {
+	case "string":
+		maybeAdd(validators, counter, "string")
+		maybeAdd(validators, counter, "transform")
+		return "pipe(string(), transform((value): string => JSON.parse(value)))"
+	case "bool":
+		maybeAdd(validators, counter, "picklist")
+		maybeAdd(validators, counter, "transform")
+		return "pipe(picklist(['true', 'false']), transform((value) => value === 'true'))"
+	}
+
+	maybeAdd(validators, counter, "string")
+	maybeAdd(validators, counter, "regex")
+	pipe := []string{"string()", "regex(" + getNumberRegex(goType) + ")"}
+
+	if isInt64Type(goType) && options.Int64 == Int64AsString {
+		return "pipe(" + strings.Join(pipe, ", ") + ")"
+	}
+
+	maybeAdd(validators, counter, "transform")
+
+	if isInt64Type(goType) && options.Int64 == Int64AsBigInt {
+		pipe = append(pipe, "transform(BigInt)")
+		return "pipe(" + strings.Join(pipe, ", ") + ")"
+	}
+
+	pipe = append(pipe, "transform(Number)")
+
+	if options.IntegerChecks {
+		pipe = append(pipe, getIntegerChecks(validators, counter, goType)...)
+	}
+
+	return "pipe(" + strings.Join(pipe, ", ") + ")"
 }
 
 func getName(namespacedName string) string {
@@ -263,9 +571,11 @@ func getNameWithPackage(namespacedName string, level int) string {
 	return output
 }
 
-func structsToValibot(structList StructList) (string, error) {
-	valibotOutput := ""
-
+// Structs with the same name in different packages
+// get more of their package path added, until they are unique.
+//
+// Map: PackagePath-Name -> output name
+func getNameMap(structList StructList, options Options) map[string]string {
 	names := make([]string, len(structList))
 	nameToIndex := make(map[string]int)
 	usedNames := make([]string, 0)
@@ -300,40 +610,79 @@ func structsToValibot(structList StructList) (string, error) {
 		}
 
 		usedNames = append(usedNames, structName)
-		nameMap[originalStruct.Name] = structName
+		nameMap[originalStruct.Name] = options.NamePrefix + structName + options.NameSuffix
 	}
 
-	importedValidators := make(map[string]uint)
-	importedValidators["object"] = 0
-	var counter uint = 1
+	return nameMap
+}
 
-	for _, s := range structList {
-		localValidbotOutput := "const " + nameMap[s.Name] + " = object({\n"
+// Generates a valibot schema for every struct, which must be in the order of OrderStructs.
+func GenerateValibot(structList StructList, options Options) (string, error) {
+	return Generate(VALIBOT_TARGET, structList, options)
+}
 
-		for _, fieldType := range s.Fields {
-			fieldOutput, err := getSingleField(importedValidators, nameMap, &counter, fieldType, 0)
-			if err != nil {
-				return "", err
-			}
+type valibotGenerator struct {
+	options Options
+	nameMap map[string]string
 
-			localValidbotOutput += fieldOutput
-		}
+	/* Namespaced names of the schemas already declared */
+	defined map[string]bool
 
-		localValidbotOutput += "});"
-		valibotOutput += "\n" + localValidbotOutput + "\n"
+	/* Namespaced names of the structs in a cycle, see getCycleStructs */
+	cycles map[string]bool
+
+	// Map: validator -> the order it was first used in,
+	// so the imports are in a stable order.
+	importedValidators map[string]uint
+	counter            uint
+}
+
+func newValibotGenerator(structList StructList, options Options) Generator {
+	return &valibotGenerator{
+		options:            options,
+		nameMap:            getNameMap(structList, options),
+		defined:            make(map[string]bool),
+		cycles:             getCycleStructs(structList),
+		importedValidators: map[string]uint{"object": 0},
+		counter:            1,
 	}
+}
 
-	validatorsArr := make([]string, len(importedValidators))
-	for k, v := range importedValidators {
+func (g *valibotGenerator) Header(structList StructList) string {
+	validatorsArr := make([]string, len(g.importedValidators))
+	for k, v := range g.importedValidators {
 		validatorsArr[v] = k
 	}
 
-	importLine := ""
-	for _, validator := range validatorsArr {
-		importLine += validator + ", "
+	return "\nimport { " + strings.Join(validatorsArr, ", ") + " } from 'valibot';\n"
+}
+
+func (g *valibotGenerator) Struct(s Struct, fields []string) string {
+	g.defined[s.Name] = true
+
+	declaration := g.nameMap[s.Name]
+	if g.cycles[s.Name] {
+		maybeAdd(g.importedValidators, &g.counter, "type GenericSchema")
+		declaration += ": GenericSchema"
 	}
 
-	importLine = "import { " + importLine[:len(importLine)-2] + " } from 'valibot';\n"
+	return "\nconst " + declaration + " = object({\n" + strings.Join(fields, "") + "});\n"
+}
+
+func (g *valibotGenerator) Field(field StructField, typeExpression string, indent uint) string {
+	return getSpaces(indent+1) + getObjectKey(field) + ": " + typeExpression + ",\n"
+}
+
+func (g *valibotGenerator) TypeExpression(field StructField, indent uint) (string, error) {
+	typeExpression, err := getStructFieldType(g.importedValidators, g.nameMap, &g.counter, field, indent, g.options)
+	if err != nil || !usesUndefinedStruct(field, g.defined) {
+		return typeExpression, err
+	}
+
+	maybeAdd(g.importedValidators, &g.counter, "lazy")
+	return "lazy(() => " + typeExpression + ")", nil
+}
 
-	return "\n" + importLine + valibotOutput, nil
+func (g *valibotGenerator) Footer(structList StructList) string {
+	return ""
 }
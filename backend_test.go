@@ -1,4 +1,4 @@
-package main
+package gobridge
 
 import "testing"
 
@@ -8,9 +8,9 @@ func TestBackendSimpleType(t *testing.T) {
 
 	var counter uint = 0
 
-	basicField := BasicStructField{name: "Name", Type: "string"}
+	basicField := BasicStructField{FieldName: "Name", Type: "string"}
 
-	output, err := getStructFieldType(validators, nameMap, &counter, basicField, 0)
+	output, err := getStructFieldType(validators, nameMap, &counter, basicField, 0, Options{})
 	expected := "string()"
 
 	t.Log(output)
@@ -47,9 +47,9 @@ func TestBackendStructType(t *testing.T) {
 	nameMap := make(map[string]string)
 	nameMap["AnotherStruct"] = "AnotherStruct"
 
-	basicField := BasicStructField{name: "Name", Type: "AnotherStruct"}
+	basicField := BasicStructField{FieldName: "Name", Type: "AnotherStruct"}
 
-	output, err := getStructFieldType(validators, nameMap, &counter, basicField, 0)
+	output, err := getStructFieldType(validators, nameMap, &counter, basicField, 0, Options{})
 	expected := "AnotherStruct"
 
 	t.Log(output)
@@ -74,10 +74,10 @@ func TestBackendArrayType(t *testing.T) {
 	validators := make(map[string]uint)
 	var counter uint = 0
 
-	arrayField := ArrayStructField{name: "Name", Type: BasicStructField{name: "Name", Type: "int64"}}
+	arrayField := ArrayStructField{FieldName: "Name", Type: BasicStructField{FieldName: "Name", Type: "int64"}}
 	nameMap := make(map[string]string)
 
-	output, err := getStructFieldType(validators, nameMap, &counter, arrayField, 0)
+	output, err := getStructFieldType(validators, nameMap, &counter, arrayField, 0, Options{})
 	expected := "array(number())"
 
 	t.Log(output)
@@ -123,9 +123,9 @@ func TestBackendArrayStructType(t *testing.T) {
 	nameMap := make(map[string]string)
 	nameMap["SomeStruct"] = "SomeStruct"
 
-	arrayField := ArrayStructField{name: "Name", Type: BasicStructField{name: "Name", Type: "SomeStruct"}}
+	arrayField := ArrayStructField{FieldName: "Name", Type: BasicStructField{FieldName: "Name", Type: "SomeStruct"}}
 
-	output, err := getStructFieldType(validators, nameMap, &counter, arrayField, 0)
+	output, err := getStructFieldType(validators, nameMap, &counter, arrayField, 0, Options{})
 	expected := "array(SomeStruct)"
 
 	t.Log(output)
@@ -159,12 +159,12 @@ func TestBackendArrayArrayType(t *testing.T) {
 	validators := make(map[string]uint)
 	var counter uint = 0
 
-	arrayField := ArrayStructField{name: "Name", Type: ArrayStructField{name: "Name", Type: BasicStructField{name: "Name", Type: "bool"}}}
+	arrayField := ArrayStructField{FieldName: "Name", Type: ArrayStructField{FieldName: "Name", Type: BasicStructField{FieldName: "Name", Type: "bool"}}}
 
 	nameMap := make(map[string]string)
 	nameMap["Name"] = "Name"
 
-	output, err := getStructFieldType(validators, nameMap, &counter, arrayField, 0)
+	output, err := getStructFieldType(validators, nameMap, &counter, arrayField, 0, Options{})
 	expected := "array(array(boolean()))"
 
 	t.Log(output)
@@ -207,13 +207,13 @@ func TestBackendMapType(t *testing.T) {
 	validators := make(map[string]uint)
 	var counter uint = 0
 
-	arrayField := MapStructField{name: "Name", KeyType: "string", Value: BasicStructField{name: "Name", Type: "uint"}}
+	arrayField := MapStructField{FieldName: "Name", KeyType: "string", Value: BasicStructField{FieldName: "Name", Type: "uint"}}
 
 	nameMap := make(map[string]string)
 	nameMap["Name"] = "Name"
 
-	output, err := getStructFieldType(validators, nameMap, &counter, arrayField, 0)
-	expected := "record(number())"
+	output, err := getStructFieldType(validators, nameMap, &counter, arrayField, 0, Options{})
+	expected := "record(string(), number())"
 
 	t.Log(output)
 
@@ -222,8 +222,8 @@ func TestBackendMapType(t *testing.T) {
 		t.FailNow()
 	}
 
-	if len(validators) != 2 {
-		t.Errorf("Validators should have length 2, got %+v\n", validators)
+	if len(validators) != 3 {
+		t.Errorf("Validators should have length 3, got %+v\n", validators)
 		t.FailNow()
 	}
 
@@ -255,13 +255,13 @@ func TestBackendMapArrayStructType(t *testing.T) {
 	validators := make(map[string]uint)
 	var counter uint = 0
 
-	arrayField := MapStructField{name: "Name", KeyType: "string", Value: ArrayStructField{name: "Name", Type: BasicStructField{name: "Name", Type: "string"}}}
+	arrayField := MapStructField{FieldName: "Name", KeyType: "string", Value: ArrayStructField{FieldName: "Name", Type: BasicStructField{FieldName: "Name", Type: "string"}}}
 
 	nameMap := make(map[string]string)
 	nameMap["Name"] = "Name"
 
-	output, err := getStructFieldType(validators, nameMap, &counter, arrayField, 0)
-	expected := "record(array(string()))"
+	output, err := getStructFieldType(validators, nameMap, &counter, arrayField, 0, Options{})
+	expected := "record(string(), array(string()))"
 
 	t.Log(output)
 
@@ -313,22 +313,22 @@ func TestBackendChaos(t *testing.T) {
 	var counter uint = 0
 
 	arrayField := ArrayStructField{
-		name: "Name",
+		FieldName: "Name",
 		Type: MapStructField{
-			name:    "Name",
-			KeyType: "string",
+			FieldName: "Name",
+			KeyType:   "string",
 			Value: ArrayStructField{
-				name: "Name", Type: MapStructField{
-					name:    "Name",
-					KeyType: "string",
+				FieldName: "Name", Type: MapStructField{
+					FieldName: "Name",
+					KeyType:   "string",
 					Value: MapStructField{
-						name:    "Name",
-						KeyType: "string",
+						FieldName: "Name",
+						KeyType:   "string",
 						Value: ArrayStructField{
-							name: "Name",
+							FieldName: "Name",
 							Type: BasicStructField{
-								name: "Name",
-								Type: "string",
+								FieldName: "Name",
+								Type:      "string",
 							},
 						},
 					},
@@ -340,8 +340,8 @@ func TestBackendChaos(t *testing.T) {
 	nameMap := make(map[string]string)
 	nameMap["Name"] = "Name"
 
-	output, err := getStructFieldType(validators, nameMap, &counter, arrayField, 0)
-	expected := "array(record(array(record(record(array(string()))))))"
+	output, err := getStructFieldType(validators, nameMap, &counter, arrayField, 0, Options{})
+	expected := "array(record(string(), array(record(string(), record(string(), array(string()))))))"
 
 	t.Log(output)
 
@@ -387,3 +387,28 @@ func TestBackendChaos(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestValibotCycle(t *testing.T) {
+	output, err := GenerateValibot(getCodeStructs(t, cycleCode), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+import { object, lazy, type GenericSchema, array } from 'valibot';
+
+const Tree: GenericSchema = object({
+  Root: lazy(() => Node),
+});
+
+const Node: GenericSchema = object({
+  Children: lazy(() => array(Node)),
+  Parent: Tree,
+});
+`
+
+	if output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+}
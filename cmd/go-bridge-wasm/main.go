@@ -0,0 +1,58 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"syscall/js"
+
+	gobridge "github.com/JohnCosta27/go-bridge"
+)
+
+// Values go through JSON, as js.ValueOf only takes []any and map[string]any.
+func toJS(value any) js.Value {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+
+	return js.Global().Get("JSON").Call("parse", string(encoded))
+}
+
+/*
+ * Sets globalThis.goBridge:
+ *
+ * goBridge.generate(code, options) -> { output, errors, warnings }
+ * goBridge.targets() -> every target, sorted, such as ["jsonschema", ..., "valibot", "zod"]
+ */
+func register() {
+	goBridge := js.Global().Get("Object").New()
+
+	goBridge.Set("generate", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) == 0 {
+			return toJS(playgroundResult{Errors: []string{"generate needs the go code"}})
+		}
+
+		optionsJSON := "{}"
+		if len(args) > 1 && !args[1].IsUndefined() && !args[1].IsNull() {
+			optionsJSON = js.Global().Get("JSON").Call("stringify", args[1]).String()
+		}
+
+		return toJS(generate(args[0].String(), optionsJSON))
+	}))
+
+	goBridge.Set("targets", js.FuncOf(func(this js.Value, args []js.Value) any {
+		return toJS(gobridge.Targets())
+	}))
+
+	js.Global().Set("goBridge", goBridge)
+}
+
+func main() {
+	register()
+
+	//
+	// The functions stop working when main returns.
+	//
+	select {}
+}
@@ -0,0 +1,45 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"slices"
+	"syscall/js"
+	"testing"
+
+	gobridge "github.com/JohnCosta27/go-bridge"
+)
+
+// Runs with node: GOOS=js GOARCH=wasm go test ./cmd/go-bridge-wasm
+func TestRegister(t *testing.T) {
+	register()
+
+	goBridge := js.Global().Get("goBridge")
+
+	options := js.Global().Get("Object").New()
+	options.Set("emitIR", true)
+
+	result := goBridge.Call("generate", "package types\n\ntype A struct {\n  B string\n}\n", options)
+	if result.Get("errors").Length() != 0 || result.Get("output").String()[0] != '{' {
+		t.Log(result.Get("output").String())
+		t.FailNow()
+	}
+
+	result = goBridge.Call("generate", "package types\n\ntype A struct {\n  B string\n}\n")
+	if result.Get("output").String() != "import { object, string } from 'valibot';\n\nconst A = object({\n  B: string(),\n});\n" {
+		t.Log(result.Get("output").String())
+		t.FailNow()
+	}
+
+	jsTargets := goBridge.Call("targets")
+
+	targets := make([]string, 0, jsTargets.Length())
+	for i := range jsTargets.Length() {
+		targets = append(targets, jsTargets.Index(i).String())
+	}
+
+	if !slices.Equal(targets, gobridge.Targets()) || !slices.Contains(targets, gobridge.VALIBOT_TARGET) {
+		t.Log(targets)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	gobridge "github.com/JohnCosta27/go-bridge"
+)
+
+// What the playground can change, the same as a job in the config file.
+type playgroundOptions struct {
+	Target        string            `json:"target"`
+	Template      string            `json:"template"`
+	EmitIR        bool              `json:"emitIR"`
+	Int64         string            `json:"int64"`
+	IntegerChecks bool              `json:"integerChecks"`
+	Prefix        string            `json:"prefix"`
+	Suffix        string            `json:"suffix"`
+	Overrides     map[string]string `json:"overrides"`
+}
+
+// Problems don't stop the output, so the playground can show both.
+type playgroundResult struct {
+	Output   string   `json:"output"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+}
+
+func (o playgroundOptions) options() (gobridge.Options, error) {
+	mode := gobridge.Int64AsNumber
+	if o.Int64 != "" {
+		var ok bool
+		mode, ok = gobridge.ParseInt64Mode(o.Int64)
+		if !ok {
+			return gobridge.Options{}, errors.New("Unknown int64 mode: " + o.Int64)
+		}
+	}
+
+	return gobridge.Options{
+		Int64:         mode,
+		IntegerChecks: o.IntegerChecks,
+		Overrides:     o.Overrides,
+		NamePrefix:    o.Prefix,
+		NameSuffix:    o.Suffix,
+	}, nil
+}
+
+// Generates the code of a single go file, optionsJSON is a playgroundOptions.
+func generate(code string, optionsJSON string) playgroundResult {
+	result := playgroundResult{Errors: make([]string, 0), Warnings: make([]string, 0)}
+
+	fail := func(err error) playgroundResult {
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+
+	var playground playgroundOptions
+	err := json.Unmarshal([]byte(optionsJSON), &playground)
+	if err != nil {
+		return fail(err)
+	}
+
+	options, err := playground.options()
+	if err != nil {
+		return fail(err)
+	}
+
+	options.Warn = func(message string) {
+		result.Warnings = append(result.Warnings, message)
+	}
+
+	options.Error = func(err error) {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	structs, err := gobridge.LoadCode(code, options)
+	if err != nil {
+		return fail(err)
+	}
+
+	var output string
+	switch {
+	case playground.EmitIR:
+		var ir []byte
+		ir, err = gobridge.MarshalIR(structs, nil)
+		output = string(ir) + "\n"
+	case playground.Template != "":
+		output, err = gobridge.GenerateTemplate("playground", playground.Template, structs, options)
+	default:
+		target := playground.Target
+		if target == "" {
+			target = gobridge.VALIBOT_TARGET
+		}
+
+		output, err = gobridge.Generate(target, structs, options)
+	}
+
+	if err != nil {
+		return fail(err)
+	}
+
+	result.Output = strings.TrimPrefix(output, "\n")
+	return result
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const playgroundCode = `
+package types
+
+type User struct {
+  ID    int64
+  Conn  chan int
+  Names []string
+}
+`
+
+func TestGenerate(t *testing.T) {
+	result := generate(playgroundCode, `{ "int64": "bigint", "suffix": "Schema" }`)
+
+	expected := `import { object, bigint, unknown, array, string } from 'valibot';
+
+const UserSchema = object({
+  ID: bigint(),
+  Conn: unknown(),
+  Names: array(string()),
+});
+`
+
+	if result.Output != expected || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "field Conn of struct User") {
+		t.Log(result)
+		t.FailNow()
+	}
+
+	result = generate(playgroundCode, `{ "template": "{{range .Structs}}{{snake (name .Name)}}{{end}}" }`)
+	if result.Output != "user" {
+		t.Log(result)
+		t.FailNow()
+	}
+
+	result = generate(playgroundCode, `{ "target": "typebox" }`)
+	if result.Output != "" || len(result.Errors) != 2 {
+		t.Log(result)
+		t.FailNow()
+	}
+
+	result = generate("package types\n\ntype A struct {", `{}`)
+	if result.Output != "" || len(result.Errors) != 1 {
+		t.Log(result)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,525 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gobridge "github.com/JohnCosta27/go-bridge"
+)
+
+type command struct {
+	Name        string
+	Args        string
+	Description string
+	Run         func(args []string) int
+}
+
+func getCommands() []command {
+	return []command{
+		{
+			Name:        "generate",
+			Args:        "[flags] [entry.go]",
+			Description: "Generates the schemas of the package of entry.go, or of every job in the config file without it.",
+			Run:         runGenerate,
+		},
+		{
+			Name:        "check",
+			Args:        "[flags] [entry.go]",
+			Description: "Compares the schemas with the output files and prints a diff, without writing anything.\nExits with 5 when any of them are out of date.",
+			Run:         runCheck,
+		},
+		{
+			Name:        "list",
+			Args:        "[flags] [entry.go]",
+			Description: "Prints every struct that would be generated, under the package it is from.",
+			Run:         runList,
+		},
+		{
+			Name:        "graph",
+			Args:        "[flags] [entry.go]",
+			Description: "Prints which structs use which, as `A -> B` lines or a graphviz digraph.",
+			Run:         runGraph,
+		},
+		{
+			Name:        "explain",
+			Args:        "[flags] <Type> [entry.go]",
+			Description: "Shows the schema of a struct, and why each of its fields has its schema.\nThe type can be written with its package path, such as models/user.User, when several packages have it.",
+			Run:         runExplain,
+		},
+		{
+			Name:        "serve",
+			Args:        "[flags] [entry.go]",
+			Description: "Serves the schemas over HTTP, reading the packages again only when they change.\nGET /schemas.ts generates a job, with ?job=, ?target= and ?type= to pick it, its target and its structs.\nPOST /convert generates the go code in the body, with ?target=.",
+			Run:         runServe,
+		},
+	}
+}
+
+func newFlagSet(c command) *flag.FlagSet {
+	flags := flag.NewFlagSet(c.Name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: go-bridge %s %s\n\n%s\n\nflags:\n", c.Name, c.Args, c.Description)
+		flags.PrintDefaults()
+	}
+
+	return flags
+}
+
+func getCommand(name string) (command, bool) {
+	for _, c := range getCommands() {
+		if c.Name == name {
+			return c, true
+		}
+	}
+
+	return command{}, false
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "usage: go-bridge <command> [flags] [entry.go]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+
+	for _, c := range getCommands() {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.Name, c.Args)
+	}
+
+	fmt.Fprintln(os.Stderr, "\nWithout a command, go-bridge generates. Run go-bridge help <command> for its flags.")
+}
+
+// Flag parsing errors have already been printed, with the usage.
+func parseFlags(flags *flag.FlagSet, args []string) (int, bool) {
+	err := flags.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return EXIT_OK, false
+	}
+
+	if err != nil {
+		return EXIT_USAGE_ERROR, false
+	}
+
+	return EXIT_OK, true
+}
+
+// Flags shared by every command that runs jobs. With an entry file
+// they describe a single job, otherwise the jobs are in the config file.
+type jobFlags struct {
+	root          *string
+	types         listFlag
+	config        *string
+	ir            *string
+	target        *string
+	template      *string
+	merge         *string
+	lock          *string
+	int64Mode     *string
+	integerChecks *bool
+	strict        *bool
+	prefix        *string
+	suffix        *string
+	overrides     overrideFlag
+}
+
+/* Only used with an entry file, as the config file has its own */
+var SINGLE_JOB_FLAGS = []string{"root", "type", "ir", "target", "template", "merge", "lock", "int64", "int-checks", "strict", "prefix", "suffix", "override", "o"}
+
+func addJobFlags(flags *flag.FlagSet) *jobFlags {
+	f := jobFlags{overrides: make(overrideFlag)}
+
+	f.root = flags.String("root", "", "The path of the root of your go project (containing go.mod). Defaults to the first directory with a go.mod, walking up from the entry file")
+	flags.Var(&f.types, "type", "Only generate this struct and the structs it uses. Can be repeated, or separated by commas")
+	f.config = flags.String("config", "", "The config file, used without an entry file. Defaults to gobridge.json or gobridge.yaml in the current directory")
+	f.ir = flags.String("ir", "", "Read the structs from an IR file written by -emit-ir, instead of an entry file")
+	f.target = flags.String("target", "", "What to generate, valibot by default: "+strings.Join(gobridge.Targets(), ", "))
+	f.template = flags.String("template", "", "Render the structs through this text/template file, instead of the target")
+	f.merge = flags.String("merge", "", "Replace components.schemas of this OpenAPI document, YAML or JSON, writing the whole document to the output")
+	f.lock = flags.String("lock", "", "Keep the field numbers of -target proto in this JSON file, so they never change")
+	f.int64Mode = flags.String("int64", string(gobridge.Int64AsNumber), "How int64 and uint64 are represented: number, bigint or string")
+	f.integerChecks = flags.Bool("int-checks", false, "Add integer() and min/max checks derived from the golang type width")
+	f.strict = flags.Bool("strict", false, "Fail when any field could not be parsed, instead of replacing it with unknown()")
+	f.prefix = flags.String("prefix", "", "Added before every schema name")
+	f.suffix = flags.String("suffix", "", "Added after every schema name, such as Schema")
+	flags.Var(f.overrides, "override", "Schema to use for a type, such as 'time.Time=pipe(string(), isoTimestamp())'. Can be repeated")
+
+	return &f
+}
+
+// The job the flags describe, when there is no config file.
+func (f *jobFlags) getJob(entry []string, outputPath string) Job {
+	return Job{
+		Entry:         entry,
+		IR:            *f.ir,
+		Types:         f.types,
+		Target:        *f.target,
+		Template:      *f.template,
+		Merge:         *f.merge,
+		Lock:          *f.lock,
+		Output:        outputPath,
+		Int64:         *f.int64Mode,
+		IntegerChecks: *f.integerChecks,
+		Strict:        *f.strict,
+		Naming:        Naming{Prefix: *f.prefix, Suffix: *f.suffix},
+		Overrides:     f.overrides,
+	}
+}
+
+func (f *jobFlags) getJobs(flags *flag.FlagSet, entryFile string, outputPath string) ([]Job, runner, error) {
+	//
+	// `//go:generate go-bridge -o ../web/api.ts` runs in the directory
+	// of the package, with the file it is in as $GOFILE.
+	//
+	goPackage := ""
+	if entryFile == "" && *f.config == "" && *f.ir == "" && os.Getenv("GOFILE") != "" {
+		entryFile = os.Getenv("GOFILE")
+		goPackage = os.Getenv("GOPACKAGE")
+	}
+
+	if *f.ir != "" {
+		if entryFile != "" {
+			return nil, runner{}, errors.New("-ir is read instead of an entry file, it can't have both")
+		}
+
+		return []Job{f.getJob(nil, outputPath)}, runner{}, nil
+	}
+
+	if entryFile != "" {
+		moduleRoot := *f.root
+		if moduleRoot == "" {
+			var err error
+			moduleRoot, err = gobridge.FindModuleRoot(filepath.Dir(entryFile))
+			if err != nil {
+				return nil, runner{}, err
+			}
+		}
+
+		rootDir, modules, err := gobridge.LoadModules(moduleRoot)
+		if err != nil {
+			return nil, runner{}, err
+		}
+
+		entryDir, err := gobridge.GetPackagePath(rootDir, filepath.Dir(entryFile))
+		if err != nil {
+			return nil, runner{}, err
+		}
+
+		err = checkGoPackage(entryFile, goPackage)
+		if err != nil {
+			return nil, runner{}, err
+		}
+
+		return []Job{f.getJob([]string{entryDir}, outputPath)}, runner{modules: modules, rootDir: getRelativeDir(rootDir)}, nil
+	}
+
+	var singleJobFlag error
+	flags.Visit(func(set *flag.Flag) {
+		for _, name := range SINGLE_JOB_FLAGS {
+			if set.Name == name {
+				singleJobFlag = errors.New("-" + name + " needs an entry file, jobs from the config file have their own")
+			}
+		}
+	})
+
+	if singleJobFlag != nil {
+		return nil, runner{}, singleJobFlag
+	}
+
+	configPath := *f.config
+	if configPath == "" {
+		path, err := findConfig(".")
+		if err != nil {
+			return nil, runner{}, err
+		}
+
+		configPath = path
+	}
+
+	config, err := readConfig(configPath)
+	if err != nil {
+		return nil, runner{}, err
+	}
+
+	//
+	// Paths in the config file are relative to it.
+	//
+	err = os.Chdir(filepath.Dir(configPath))
+	if err != nil {
+		return nil, runner{}, err
+	}
+
+	moduleRoot, err := gobridge.FindModuleRoot(".")
+	if err != nil {
+		return nil, runner{}, err
+	}
+
+	rootDir, modules, err := gobridge.LoadModules(moduleRoot)
+	if err != nil {
+		return nil, runner{}, err
+	}
+
+	for i, job := range config.Jobs {
+		for j, entry := range job.Entry {
+			config.Jobs[i].Entry[j], err = gobridge.GetPackagePath(rootDir, entry)
+			if err != nil {
+				return nil, runner{}, err
+			}
+		}
+	}
+
+	return config.Jobs, runner{modules: modules, rootDir: getRelativeDir(rootDir), verbose: true}, nil
+}
+
+func runGenerate(args []string) int {
+	c, _ := getCommand("generate")
+	flags := newFlagSet(c)
+	f := addJobFlags(flags)
+	outputPath := flags.String("o", "", "Write the schemas to this file instead of stdout. It is left untouched on failure or when nothing changed")
+	watch := flags.Bool("watch", false, "Keep running, and regenerate the output files when a go file in one of the packages changes")
+	check := flags.Bool("check", false, "The same as go-bridge check")
+	emitIR := flags.Bool("emit-ir", false, "Write the structs as versioned JSON instead of the target, for other tools or -ir")
+
+	exitCode, ok := parseFlags(flags, args)
+	if !ok {
+		return exitCode
+	}
+
+	if *check && *watch {
+		fmt.Fprintln(os.Stderr, "-watch can't be used with -check")
+		return EXIT_USAGE_ERROR
+	}
+
+	jobs, r, err := f.getJobs(flags, flags.Arg(0), *outputPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: "+err.Error())
+		return EXIT_USAGE_ERROR
+	}
+
+	r.emitIR = *emitIR
+
+	if *check {
+		return checkJobs(r, jobs)
+	}
+
+	for _, job := range jobs {
+		if *watch && job.Output == "" {
+			fmt.Fprintln(os.Stderr, "-watch needs an output file to write to")
+			return EXIT_USAGE_ERROR
+		}
+	}
+
+	r.verbose = r.verbose || *watch
+	return r.run(jobs, *watch)
+}
+
+func runCheck(args []string) int {
+	c, _ := getCommand("check")
+	flags := newFlagSet(c)
+	f := addJobFlags(flags)
+	outputPath := flags.String("o", "", "The file to compare the schemas with")
+
+	exitCode, ok := parseFlags(flags, args)
+	if !ok {
+		return exitCode
+	}
+
+	jobs, r, err := f.getJobs(flags, flags.Arg(0), *outputPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: "+err.Error())
+		return EXIT_USAGE_ERROR
+	}
+
+	return checkJobs(r, jobs)
+}
+
+func checkJobs(r runner, jobs []Job) int {
+	for _, job := range jobs {
+		if job.Output == "" {
+			fmt.Fprintln(os.Stderr, "check needs an output file to compare against")
+			return EXIT_USAGE_ERROR
+		}
+	}
+
+	r.check = true
+	return r.run(jobs, false)
+}
+
+// go generate tells us the package it ran for,
+// which should be the package of the entry file.
+func checkGoPackage(entryFile string, goPackage string) error {
+	if goPackage == "" {
+		return nil
+	}
+
+	astFile, err := parser.ParseFile(token.NewFileSet(), entryFile, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return err
+	}
+
+	if astFile.Name.Name != goPackage {
+		return errors.New("go generate ran for package " + goPackage + ", but " + entryFile + " is package " + astFile.Name.Name)
+	}
+
+	return nil
+}
+
+// Keeps file names in errors short, when they are close to the current directory.
+func getRelativeDir(dir string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return dir
+	}
+
+	absoluteDir, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+
+	relativeDir, err := filepath.Rel(cwd, absoluteDir)
+	if err != nil {
+		return dir
+	}
+
+	return relativeDir
+}
+
+// Parses the structs of every job, for the commands that look
+// at them instead of generating schemas.
+// `inspect` only needs to succeed for one of the jobs, as a type
+// we are looking for is usually in one of them.
+func inspectJobs(flags *flag.FlagSet, f *jobFlags, entryFile string, inspect func(job Job, structs gobridge.StructList, options gobridge.Options) (string, error)) int {
+	jobs, r, err := f.getJobs(flags, entryFile, "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: "+err.Error())
+		return EXIT_USAGE_ERROR
+	}
+
+	cache := gobridge.NewPackageCache(r.rootDir, r.modules)
+	var inspectErr error
+	inspected := false
+
+	for _, job := range jobs {
+		options, err := job.options()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error: "+err.Error())
+			return EXIT_USAGE_ERROR
+		}
+
+		options.Error = printError
+
+		structs, _, err := loadStructs(cache, job, options)
+		if err != nil {
+			printError(err)
+			return getExitCode(err)
+		}
+
+		output, err := inspect(job, structs, options)
+		if err != nil {
+			inspectErr = err
+			continue
+		}
+
+		if len(jobs) > 1 {
+			fmt.Println("# " + job.Name)
+		}
+
+		fmt.Print(output)
+		inspected = true
+	}
+
+	if !inspected {
+		printError(inspectErr)
+		return EXIT_USAGE_ERROR
+	}
+
+	return EXIT_OK
+}
+
+func runList(args []string) int {
+	c, _ := getCommand("list")
+	flags := newFlagSet(c)
+	f := addJobFlags(flags)
+
+	exitCode, ok := parseFlags(flags, args)
+	if !ok {
+		return exitCode
+	}
+
+	return inspectJobs(flags, f, flags.Arg(0), func(job Job, structs gobridge.StructList, options gobridge.Options) (string, error) {
+		return gobridge.ListStructs(structs), nil
+	})
+}
+
+func runGraph(args []string) int {
+	c, _ := getCommand("graph")
+	flags := newFlagSet(c)
+	f := addJobFlags(flags)
+	dot := flags.Bool("dot", false, "Print a graphviz digraph, for `dot -Tsvg`")
+
+	exitCode, ok := parseFlags(flags, args)
+	if !ok {
+		return exitCode
+	}
+
+	return inspectJobs(flags, f, flags.Arg(0), func(job Job, structs gobridge.StructList, options gobridge.Options) (string, error) {
+		return gobridge.GraphStructs(structs, *dot), nil
+	})
+}
+
+func runExplain(args []string) int {
+	c, _ := getCommand("explain")
+	flags := newFlagSet(c)
+	f := addJobFlags(flags)
+
+	exitCode, ok := parseFlags(flags, args)
+	if !ok {
+		return exitCode
+	}
+
+	typeName := flags.Arg(0)
+	if typeName == "" {
+		fmt.Fprintln(os.Stderr, "Please type the struct to explain")
+		flags.Usage()
+		return EXIT_USAGE_ERROR
+	}
+
+	return inspectJobs(flags, f, flags.Arg(1), func(job Job, structs gobridge.StructList, options gobridge.Options) (string, error) {
+		return gobridge.ExplainStruct(structs, typeName, options)
+	})
+}
+
+func run() int {
+	args := os.Args[1:]
+
+	if len(args) > 0 && (args[0] == "help" || args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		if len(args) > 1 {
+			c, exists := getCommand(args[1])
+			if exists {
+				return c.Run([]string{"-help"})
+			}
+		}
+
+		printUsage()
+		return EXIT_OK
+	}
+
+	if len(args) > 0 {
+		c, exists := getCommand(args[0])
+		if exists {
+			return c.Run(args[1:])
+		}
+	}
+
+	//
+	// `go-bridge [flags] entry.go` from before there were commands.
+	//
+	if len(args) == 0 && os.Getenv("GOFILE") == "" {
+		printUsage()
+		return EXIT_USAGE_ERROR
+	}
+
+	return runGenerate(args)
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	gobridge "github.com/JohnCosta27/go-bridge"
+	"gopkg.in/yaml.v3"
+)
+
+/* Looked for in this order, in the project root */
+var CONFIG_FILES = []string{"gobridge.json", "gobridge.yaml", "gobridge.yml"}
+
+// Paths are relative to the directory of the config file,
+// which is the root of the project.
+type Config struct {
+	Jobs []Job `json:"jobs" yaml:"jobs"`
+}
+
+// One output file, and everything needed to generate it.
+type Job struct {
+	/* Used in messages, defaults to the output path */
+	Name string `json:"name" yaml:"name"`
+
+	/* Directories of the packages to generate schemas for */
+	Entry []string `json:"entry" yaml:"entry"`
+
+	/* An IR file written by -emit-ir, read instead of entry packages */
+	IR string `json:"ir" yaml:"ir"`
+
+	// Only these structs and their dependencies are generated,
+	// instead of every struct in the entry packages.
+	Types []string `json:"types" yaml:"types"`
+
+	/* One of gobridge.Targets(), valibot by default */
+	Target string `json:"target" yaml:"target"`
+
+	/* A text/template file rendered instead of the target */
+	Template string `json:"template" yaml:"template"`
+
+	// An OpenAPI document, written to the output with
+	// its components.schemas replaced by the structs.
+	Merge string `json:"merge" yaml:"merge"`
+
+	// The field numbers of the proto target, kept between runs
+	// so a field never gets a different number.
+	Lock string `json:"lock" yaml:"lock"`
+
+	/* Printed to stdout when empty */
+	Output string `json:"output" yaml:"output"`
+
+	Int64         string `json:"int64" yaml:"int64"`
+	IntegerChecks bool   `json:"integerChecks" yaml:"integerChecks"`
+	Strict        bool   `json:"strict" yaml:"strict"`
+
+	Naming Naming `json:"naming" yaml:"naming"`
+
+	// Map: PackageName.TypeName -> Schema
+	Overrides map[string]string `json:"overrides" yaml:"overrides"`
+
+	// Map: PackageName.InterfaceName -> what follows gobridge:union
+	Unions map[string]string `json:"unions" yaml:"unions"`
+}
+
+type Naming struct {
+	Prefix string `json:"prefix" yaml:"prefix"`
+	Suffix string `json:"suffix" yaml:"suffix"`
+}
+
+func findConfig(dir string) (string, error) {
+	for _, name := range CONFIG_FILES {
+		path := filepath.Join(dir, name)
+
+		_, err := os.Stat(path)
+		if err == nil {
+			return path, nil
+		}
+	}
+
+	return "", errors.New("Could not find a gobridge.json or gobridge.yaml file in " + dir)
+}
+
+func readConfig(path string) (Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var config Config
+
+	//
+	// Unknown keys are errors, so a typo doesn't
+	// silently generate something different.
+	//
+	if filepath.Ext(path) == ".json" {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&config)
+	}
+
+	if err != nil {
+		return Config{}, errors.New("Could not read " + path + ": " + err.Error())
+	}
+
+	if len(config.Jobs) == 0 {
+		return Config{}, errors.New(path + " does not have any jobs")
+	}
+
+	for i, job := range config.Jobs {
+		if job.Name == "" {
+			config.Jobs[i].Name = job.Output
+		}
+
+		if len(job.Entry) == 0 && job.IR == "" {
+			return Config{}, errors.New("Job " + config.Jobs[i].Name + " does not have any entry packages")
+		}
+
+		if len(job.Entry) > 0 && job.IR != "" {
+			return Config{}, errors.New("Job " + config.Jobs[i].Name + " has entry packages and an IR file, it can only have one of them")
+		}
+	}
+
+	return config, nil
+}
+
+func (job Job) target() string {
+	if job.Target == "" {
+		return gobridge.VALIBOT_TARGET
+	}
+
+	return job.Target
+}
+
+// Also checks the parts of the job that flags would have checked.
+func (job Job) options() (gobridge.Options, error) {
+	if !slices.Contains(gobridge.Targets(), job.target()) {
+		return gobridge.Options{}, errors.New("Unknown target " + job.Target + ", the targets are " + strings.Join(gobridge.Targets(), ", "))
+	}
+
+	if job.Target != "" && job.Template != "" {
+		return gobridge.Options{}, errors.New("A job can have a target or a template, not both")
+	}
+
+	if job.Merge != "" && (job.Template != "" || (job.Target != "" && !strings.HasPrefix(job.Target, gobridge.OPENAPI_TARGET))) {
+		return gobridge.Options{}, errors.New("Only OpenAPI schemas can be merged into an OpenAPI document")
+	}
+
+	if job.Lock != "" && job.Target != gobridge.PROTOBUF_TARGET {
+		return gobridge.Options{}, errors.New("A lock file only has the field numbers of the proto target")
+	}
+
+	//
+	// The IR already has only the structs that were needed.
+	//
+	if job.IR != "" && len(job.Types) > 0 {
+		return gobridge.Options{}, errors.New("Types can't be picked from an IR file, only when it is written")
+	}
+
+	mode := gobridge.Int64AsNumber
+	if job.Int64 != "" {
+		var ok bool
+		mode, ok = gobridge.ParseInt64Mode(job.Int64)
+		if !ok {
+			return gobridge.Options{}, errors.New("Unknown int64 mode: " + job.Int64)
+		}
+	}
+
+	return gobridge.Options{
+		Int64:         mode,
+		IntegerChecks: job.IntegerChecks,
+		Overrides:     job.Overrides,
+		Unions:        job.Unions,
+		NamePrefix:    job.Naming.Prefix,
+		NameSuffix:    job.Naming.Suffix,
+	}, nil
+}
+
+// The field numbers in the lock file of the job,
+// which are all new when it doesn't exist yet.
+func (job Job) readLock() (gobridge.FieldNumbers, error) {
+	numbers := make(gobridge.FieldNumbers)
+	if job.Lock == "" {
+		return numbers, nil
+	}
+
+	content, err := os.ReadFile(job.Lock)
+	if errors.Is(err, os.ErrNotExist) {
+		return numbers, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, &numbers)
+	if err != nil {
+		return nil, errors.New("Could not read " + job.Lock + ": " + err.Error())
+	}
+
+	return numbers, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gobridge "github.com/JohnCosta27/go-bridge"
+)
+
+func writeConfig(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestReadConfig(t *testing.T) {
+	jsonPath := writeConfig(t, "gobridge.json", `{
+  "jobs": [
+    {
+      "entry": ["models"],
+      "types": ["User"],
+      "output": "web/user.ts",
+      "int64": "bigint",
+      "naming": { "suffix": "Schema" },
+      "overrides": { "time.Time": "pipe(string(), isoTimestamp())" },
+      "unions": { "models.Shape": "kind Circle=circle" }
+    }
+  ]
+}`)
+
+	yamlPath := writeConfig(t, "gobridge.yaml", `
+jobs:
+  - entry: [models]
+    types: [User]
+    output: web/user.ts
+    int64: bigint
+    naming:
+      suffix: Schema
+    overrides:
+      time.Time: pipe(string(), isoTimestamp())
+    unions:
+      models.Shape: kind Circle=circle
+`)
+
+	for _, path := range []string{jsonPath, yamlPath} {
+		config, err := readConfig(path)
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+
+		job := config.Jobs[0]
+		if job.Name != "web/user.ts" || job.Entry[0] != "models" || job.Types[0] != "User" {
+			t.Log(job)
+			t.FailNow()
+		}
+
+		options, err := job.options()
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+
+		if options.Int64 != gobridge.Int64AsBigInt || options.NameSuffix != "Schema" || options.Overrides["time.Time"] != "pipe(string(), isoTimestamp())" || options.Unions["models.Shape"] != "kind Circle=circle" {
+			t.Log(options)
+			t.FailNow()
+		}
+	}
+}
+
+func TestReadConfigErrors(t *testing.T) {
+	tests := map[string]string{
+		"Unknown key":  `{ "jobs": [{ "entry": ["models"], "outptu": "web/user.ts" }] }`,
+		"No jobs":      `{ "jobs": [] }`,
+		"No entry":     `{ "jobs": [{ "output": "web/user.ts" }] }`,
+		"Entry and IR": `{ "jobs": [{ "entry": ["models"], "ir": "models.json" }] }`,
+		"Invalid json": `{ "jobs": `,
+		"Wrong type":   `{ "jobs": [{ "entry": "models" }] }`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := readConfig(writeConfig(t, "gobridge.json", content))
+			if err == nil {
+				t.FailNow()
+			}
+		})
+	}
+
+	_, err := Job{Entry: []string{"models"}, Target: "typebox"}.options()
+	if err == nil {
+		t.Log("Unknown targets should fail")
+		t.FailNow()
+	}
+
+	_, err = Job{IR: "models.json", Types: []string{"User"}}.options()
+	if err == nil {
+		t.Log("Types can't be picked from an IR file")
+		t.FailNow()
+	}
+
+	_, err = Job{Entry: []string{"models"}, Merge: "api.yaml", Target: "pydantic"}.options()
+	if err == nil {
+		t.Log("Only OpenAPI schemas can be merged")
+		t.FailNow()
+	}
+
+	_, err = Job{Entry: []string{"models"}, Lock: "models.lock"}.options()
+	if err == nil {
+		t.Log("Lock files are only for the proto target")
+		t.FailNow()
+	}
+}
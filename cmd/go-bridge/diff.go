@@ -0,0 +1,201 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+/* Unchanged lines shown around every change */
+const DIFF_CONTEXT = 3
+
+// Lines compared with each other at most, past which the changed
+// lines are all removed and then added, instead of running out of memory.
+const MAX_DIFF_CELLS = 4_000_000
+
+/* Follows the last line when it doesn't end with a newline */
+const NO_NEWLINE = "\\ No newline at end of file"
+
+type diffLine struct {
+	// ' ' for unchanged, '-' for removed and '+' for added lines.
+	Kind byte
+	Text string
+}
+
+// A last line without a newline is a different line
+// than the same one with it, so the diff shows the newline.
+func splitLines(content string) []string {
+	if content == "" {
+		return []string{}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if !strings.HasSuffix(content, "\n") {
+		lines[len(lines)-1] += "\n" + NO_NEWLINE
+	}
+
+	return lines
+}
+
+// Longest common subsequence of the lines, after skipping the
+// common start and end, which is usually most of a generated file.
+func diffLines(from []string, to []string) []diffLine {
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix && from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+
+	fromMiddle := from[prefix : len(from)-suffix]
+	toMiddle := to[prefix : len(to)-suffix]
+
+	lines := make([]diffLine, 0, len(from)+len(to))
+	for _, line := range from[:prefix] {
+		lines = append(lines, diffLine{Kind: ' ', Text: line})
+	}
+
+	if len(fromMiddle)*len(toMiddle) > MAX_DIFF_CELLS {
+		lines = append(lines, replaceLines(fromMiddle, toMiddle)...)
+	} else {
+		lines = append(lines, diffMiddle(fromMiddle, toMiddle)...)
+	}
+
+	for _, line := range from[len(from)-suffix:] {
+		lines = append(lines, diffLine{Kind: ' ', Text: line})
+	}
+
+	return lines
+}
+
+// Every line removed, and then every line added.
+func replaceLines(from []string, to []string) []diffLine {
+	lines := make([]diffLine, 0, len(from)+len(to))
+	for _, line := range from {
+		lines = append(lines, diffLine{Kind: '-', Text: line})
+	}
+
+	for _, line := range to {
+		lines = append(lines, diffLine{Kind: '+', Text: line})
+	}
+
+	return lines
+}
+
+func diffMiddle(from []string, to []string) []diffLine {
+	//
+	// lengths[i][j] is the length of the longest common subsequence
+	// of from[i:] and to[j:].
+	//
+	lengths := make([][]int, len(from)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(to)+1)
+	}
+
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, len(from)+len(to))
+
+	i, j := 0, 0
+	for i < len(from) || j < len(to) {
+		switch {
+		case i < len(from) && j < len(to) && from[i] == to[j]:
+			lines = append(lines, diffLine{Kind: ' ', Text: from[i]})
+			i++
+			j++
+		case i < len(from) && (j == len(to) || lengths[i+1][j] >= lengths[i][j+1]):
+			lines = append(lines, diffLine{Kind: '-', Text: from[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{Kind: '+', Text: to[j]})
+			j++
+		}
+	}
+
+	return lines
+}
+
+func getHunkRange(start int, count int) string {
+	// An empty range points at the line before it.
+	if count == 0 {
+		start--
+	}
+
+	return strconv.Itoa(start) + "," + strconv.Itoa(count)
+}
+
+// Returns an empty string when both are the same.
+func unifiedDiff(fromName string, toName string, from string, to string) string {
+	lines := diffLines(splitLines(from), splitLines(to))
+
+	changes := make([]int, 0)
+	for i, line := range lines {
+		if line.Kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString("--- " + fromName + "\n")
+	builder.WriteString("+++ " + toName + "\n")
+
+	fromLine, toLine := 1, 1
+	position := 0
+
+	for c := 0; c < len(changes); {
+		//
+		// Changes close enough to share their context
+		// go in the same hunk.
+		//
+		last := c
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*DIFF_CONTEXT+1 {
+			last++
+		}
+
+		start := max(changes[c]-DIFF_CONTEXT, 0)
+		end := min(changes[last]+DIFF_CONTEXT+1, len(lines))
+
+		for ; position < start; position++ {
+			fromLine++
+			toLine++
+		}
+
+		fromCount, toCount := 0, 0
+		for _, line := range lines[start:end] {
+			if line.Kind != '+' {
+				fromCount++
+			}
+			if line.Kind != '-' {
+				toCount++
+			}
+		}
+
+		builder.WriteString("@@ -" + getHunkRange(fromLine, fromCount) + " +" + getHunkRange(toLine, toCount) + " @@\n")
+
+		for _, line := range lines[start:end] {
+			builder.WriteByte(line.Kind)
+			builder.WriteString(line.Text + "\n")
+		}
+
+		fromLine += fromCount
+		toLine += toCount
+		position = end
+		c = last + 1
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	from := `a
+b
+c
+d
+e
+f
+g
+h
+i
+j
+`
+
+	to := `a
+b
+C
+d
+e
+f
+g
+h
+i
+j
+k
+`
+
+	expected := `--- old
++++ new
+@@ -1,6 +1,6 @@
+ a
+ b
+-c
++C
+ d
+ e
+ f
+@@ -8,3 +8,4 @@
+ h
+ i
+ j
++k
+`
+
+	diff := unifiedDiff("old", "new", from, to)
+	if diff != expected {
+		t.Log(diff)
+		t.FailNow()
+	}
+
+	if unifiedDiff("old", "new", from, from) != "" {
+		t.Log("Same content should have no diff")
+		t.FailNow()
+	}
+
+	expectedEmpty := `--- old
++++ new
+@@ -0,0 +1,2 @@
++a
++b
+`
+
+	diff = unifiedDiff("old", "new", "", "a\nb\n")
+	if diff != expectedEmpty {
+		t.Log(diff)
+		t.FailNow()
+	}
+}
+
+func TestUnifiedDiffNewline(t *testing.T) {
+	expected := `--- old
++++ new
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`
+
+	diff := unifiedDiff("old", "new", "a\nb", "a\nb\n")
+	if diff != expected {
+		t.Log(diff)
+		t.FailNow()
+	}
+}
+
+func TestUnifiedDiffLarge(t *testing.T) {
+	from := strings.Repeat("a\n", 3000)
+	to := strings.Repeat("b\n", 3000)
+
+	diff := unifiedDiff("old", "new", from, to)
+	if strings.Count(diff, "\n-a") != 3000 || strings.Count(diff, "\n+b") != 3000 {
+		t.Log(diff)
+		t.FailNow()
+	}
+}
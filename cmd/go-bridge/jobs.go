@@ -0,0 +1,263 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	gobridge "github.com/JohnCosta27/go-bridge"
+)
+
+// The structs of the job, from its IR file or its entry packages.
+// Also returns the directories of the packages they came from.
+func loadStructs(cache *gobridge.PackageCache, job Job, options gobridge.Options) (gobridge.StructList, []string, error) {
+	if job.IR != "" {
+		return gobridge.ReadIR(job.IR)
+	}
+
+	return gobridge.Load(cache, job.Entry, job.Types, options)
+}
+
+// Also returns the directories of the packages the output came from.
+func (r runner) parseJob(cache *gobridge.PackageCache, job Job, options gobridge.Options) (string, []string, error) {
+	structs, packageDirs, err := loadStructs(cache, job, options)
+	if err != nil {
+		return "", nil, err
+	}
+
+	//
+	// JSON has no comments, so the IR can't have the header.
+	// It lists the packages itself.
+	//
+	if r.emitIR {
+		ir, err := gobridge.MarshalIR(structs, packageDirs)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return string(ir) + "\n", packageDirs, nil
+	}
+
+	//
+	// Templates write their own header, as they could be any language.
+	//
+	if job.Template != "" {
+		templateText, err := os.ReadFile(job.Template)
+		if err != nil {
+			return "", nil, err
+		}
+
+		output, err := gobridge.GenerateTemplate(filepath.Base(job.Template), string(templateText), structs, options)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return output, packageDirs, nil
+	}
+
+	//
+	// The rest of the document isn't generated, so it doesn't get the header.
+	//
+	if job.Merge != "" {
+		document, err := os.ReadFile(job.Merge)
+		if err != nil {
+			return "", nil, err
+		}
+
+		output, err := gobridge.MergeOpenAPI(document, structs, options)
+		if err != nil {
+			return "", nil, errors.New("Could not merge into " + job.Merge + ": " + err.Error())
+		}
+
+		return string(output), packageDirs, nil
+	}
+
+	options.Header = getHeader(packageDirs)
+
+	output, err := gobridge.Generate(job.target(), structs, options)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return output, packageDirs, nil
+}
+
+// Runs jobs from the CLI, printing their problems as it goes.
+type runner struct {
+	/* The directory of go.mod, or go.work in a workspace */
+	rootDir string
+	modules gobridge.Modules
+
+	/* Compare with the output files instead of writing them */
+	check bool
+
+	/* Print every file that is written */
+	verbose bool
+
+	/* Write the IR as JSON, instead of the target */
+	emitIR bool
+}
+
+// Returns the packages the job used, which is nil when it failed
+// before we knew them.
+func (r runner) runJob(cache *gobridge.PackageCache, job Job) ([]string, int) {
+	options, err := job.options()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: "+err.Error())
+		return nil, EXIT_USAGE_ERROR
+	}
+
+	options.FieldNumbers, err = job.readLock()
+	if err != nil {
+		printError(err)
+		return nil, EXIT_USAGE_ERROR
+	}
+
+	diagnostics := make([]error, 0)
+	warnings := 0
+
+	options.Warn = func(message string) {
+		warnings++
+		fmt.Fprintln(os.Stderr, "warning: "+message)
+	}
+
+	options.Error = func(err error) {
+		diagnostics = append(diagnostics, err)
+		printError(err)
+	}
+
+	//
+	// Nothing is written to stdout unless we succeed,
+	// so a failure never ends up in the generated file.
+	//
+	output, packageDirs, err := r.parseJob(cache, job, options)
+	if err != nil {
+		printError(err)
+		return nil, getExitCode(err)
+	}
+
+	printSummary(len(diagnostics), warnings)
+
+	if job.Strict && len(diagnostics) > 0 {
+		return packageDirs, getExitCode(diagnostics[0])
+	}
+
+	//
+	// A new field changes the output too, so -check
+	// finds an old lock file without writing it.
+	//
+	if r.check {
+		if job.Output == "" {
+			fmt.Print(output)
+			return packageDirs, EXIT_OK
+		}
+
+		return packageDirs, checkOutput(job.Output, output)
+	}
+
+	if job.Lock != "" {
+		err = writeLock(job.Lock, options.FieldNumbers)
+		if err != nil {
+			printError(err)
+			return packageDirs, EXIT_WRITE_ERROR
+		}
+	}
+
+	if job.Output == "" {
+		fmt.Print(output)
+		return packageDirs, EXIT_OK
+	}
+
+	written, err := writeOutput(job.Output, output)
+	if err != nil {
+		printError(err)
+		return packageDirs, EXIT_WRITE_ERROR
+	}
+
+	if written && r.verbose {
+		fmt.Fprintln(os.Stderr, "wrote "+job.Output)
+	}
+
+	return packageDirs, EXIT_OK
+}
+
+// Every job shares the same packages, so each one is only read once.
+// Returns every package used, or nil if we don't know them all
+// as a job failed, and the exit code of the first job that failed.
+func (r runner) runJobs(jobs []Job) ([]string, int) {
+	cache := gobridge.NewPackageCache(r.rootDir, r.modules)
+
+	usedDirs := make([]string, 0)
+	exitCode := EXIT_OK
+	failed := false
+
+	for _, job := range jobs {
+		packageDirs, jobExitCode := r.runJob(cache, job)
+		if packageDirs == nil {
+			failed = true
+		}
+
+		for _, dir := range packageDirs {
+			dir = filepath.Clean(dir)
+			if !slices.Contains(usedDirs, dir) {
+				usedDirs = append(usedDirs, dir)
+			}
+		}
+
+		if exitCode == EXIT_OK {
+			exitCode = jobExitCode
+		}
+	}
+
+	if failed {
+		return nil, exitCode
+	}
+
+	sort.Strings(usedDirs)
+	return usedDirs, exitCode
+}
+
+// Package paths are relative to the project root, not the current directory.
+func (r runner) getDirs(packagePaths []string) []string {
+	dirs := make([]string, 0, len(packagePaths))
+	for _, packagePath := range packagePaths {
+		dirs = append(dirs, filepath.Join(r.rootDir, packagePath))
+	}
+
+	return dirs
+}
+
+func (r runner) run(jobs []Job, watch bool) int {
+	packageDirs, exitCode := r.runJobs(jobs)
+	if !watch {
+		return exitCode
+	}
+
+	//
+	// Before the jobs first succeed we don't know their dependencies,
+	// but the entry packages are enough to notice them being fixed.
+	//
+	if packageDirs == nil {
+		for _, job := range jobs {
+			packageDirs = append(packageDirs, job.Entry...)
+		}
+	}
+
+	//
+	// Packages are read again every time,
+	// as the cache would have the old structs.
+	//
+	watchDirs(r.getDirs(packageDirs), func() []string {
+		packageDirs, _ := r.runJobs(jobs)
+		if packageDirs == nil {
+			return nil
+		}
+
+		return r.getDirs(packageDirs)
+	})
+
+	return EXIT_OK
+}
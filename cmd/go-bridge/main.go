@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+
+	gobridge "github.com/JohnCosta27/go-bridge"
+)
+
+// Collects repeated `-override Type=schema` flags.
+type overrideFlag map[string]string
+
+func (o overrideFlag) String() string {
+	overrides := make([]string, 0, len(o))
+	for k, v := range o {
+		overrides = append(overrides, k+"="+v)
+	}
+
+	return strings.Join(overrides, ", ")
+}
+
+func (o overrideFlag) Set(value string) error {
+	typeName, schema, ok := strings.Cut(value, "=")
+	if !ok || typeName == "" || schema == "" {
+		return errors.New("Overrides look like package.Type=schema")
+	}
+
+	o[typeName] = schema
+	return nil
+}
+
+// Collects repeated flags, which can also be separated by commas.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			*l = append(*l, item)
+		}
+	}
+
+	return nil
+}
+
+const (
+	EXIT_OK          = 0
+	EXIT_PARSE_ERROR = 1
+	EXIT_USAGE_ERROR = 2
+	EXIT_UNSUPPORTED = 3
+	EXIT_WRITE_ERROR = 4
+	EXIT_STALE       = 5
+)
+
+// Problems with the go code we couldn't support exit differently
+// to go code we couldn't read.
+func getExitCode(err error) int {
+	var unsupported gobridge.UnsupportedError
+	if errors.As(err, &unsupported) {
+		return EXIT_UNSUPPORTED
+	}
+
+	return EXIT_PARSE_ERROR
+}
+
+// Errors with a position are printed as `file:line:column: message`,
+// so editors can jump to them.
+func printError(err error) {
+	var sourceError gobridge.SourceError
+	if errors.As(err, &sourceError) {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, "error: "+err.Error())
+}
+
+func pluralise(count int, word string) string {
+	if count == 1 {
+		return "1 " + word
+	}
+
+	return strconv.Itoa(count) + " " + word + "s"
+}
+
+func printSummary(errorCount int, warningCount int) {
+	if errorCount == 0 && warningCount == 0 {
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, pluralise(errorCount, "error")+", "+pluralise(warningCount, "warning"))
+}
+
+// A missing file is stale, every line of it is missing.
+func checkOutput(outputPath string, output string) int {
+	existing, err := os.ReadFile(outputPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		printError(err)
+		return EXIT_PARSE_ERROR
+	}
+
+	if bytes.Equal(existing, []byte(output)) && err == nil {
+		return EXIT_OK
+	}
+
+	fmt.Print(unifiedDiff(outputPath, outputPath+" (generated)", string(existing), output))
+	fmt.Fprintln(os.Stderr, outputPath+" is out of date, run go-bridge again to update it")
+	return EXIT_STALE
+}
+
+func main() {
+	os.Exit(run())
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+
+	gobridge "github.com/JohnCosta27/go-bridge"
+)
+
+func TestExitCodes(t *testing.T) {
+	t.Run("Unsupported types", func(t *testing.T) {
+		_, err := gobridge.CodeParse(`
+package types
+
+type A struct {
+  C chan int
+}
+`)
+
+		if getExitCode(err) != EXIT_UNSUPPORTED {
+			t.Log(err)
+			t.FailNow()
+		}
+	})
+
+	t.Run("Invalid go code", func(t *testing.T) {
+		_, err := gobridge.CodeParse(`
+package types
+
+type A struct {
+`)
+
+		if getExitCode(err) != EXIT_PARSE_ERROR {
+			t.Log(err)
+			t.FailNow()
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	gobridge "github.com/JohnCosta27/go-bridge"
+)
+
+// Matches the convention tools use to recognise generated files,
+// once it is a comment of the target.
+const GENERATED_HEADER = "Code generated by go-bridge. DO NOT EDIT."
+
+// Package directories are relative to the project root,
+// so the header is the same on every machine.
+func getHeader(packageDirs []string) string {
+	packages := make([]string, 0, len(packageDirs))
+	for _, dir := range packageDirs {
+		packages = append(packages, filepath.ToSlash(dir))
+	}
+
+	return GENERATED_HEADER + "\nSource packages: " + strings.Join(packages, ", ") + "\n"
+}
+
+// Writes to a temporary file next to `path` and renames it over,
+// so readers never see a half written file.
+// Returns false when the file already had this content, and was left alone
+// so dev servers watching it don't rebuild.
+func writeOutput(path string, content string) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err == nil && bytes.Equal(existing, []byte(content)) {
+		return false, nil
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return false, err
+	}
+
+	//
+	// Removing fails once the file has been renamed,
+	// so this only cleans up after an error.
+	//
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.WriteString(content)
+	if err != nil {
+		tempFile.Close()
+		return false, err
+	}
+
+	err = tempFile.Close()
+	if err != nil {
+		return false, err
+	}
+
+	err = os.Chmod(tempFile.Name(), 0644)
+	if err != nil {
+		return false, err
+	}
+
+	err = os.Rename(tempFile.Name(), path)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Writes the field numbers of the proto target, only when they changed.
+func writeLock(path string, numbers gobridge.FieldNumbers) error {
+	content, err := json.MarshalIndent(numbers, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = writeOutput(path, string(content)+"\n")
+	return err
+}
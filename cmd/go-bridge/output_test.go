@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHeader(t *testing.T) {
+	header := getHeader([]string{"models", "models/nested"})
+
+	expected := `Code generated by go-bridge. DO NOT EDIT.
+Source packages: models, models/nested
+`
+
+	if header != expected {
+		t.Log(header)
+		t.FailNow()
+	}
+}
+
+func TestWriteOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schemas.ts")
+
+	written, err := writeOutput(path, "const A = object({});\n")
+	if err != nil || !written {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	written, err = writeOutput(path, "const A = object({});\n")
+	if err != nil || written {
+		t.Log("Unchanged output should not be written")
+		t.FailNow()
+	}
+
+	written, err = writeOutput(path, "const B = object({});\n")
+	if err != nil || !written {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != "const B = object({});\n" {
+		t.Log(string(content))
+		t.FailNow()
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil || len(entries) != 1 {
+		t.Log("Temporary files should be cleaned up", entries)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	gobridge "github.com/JohnCosta27/go-bridge"
+)
+
+/* Go code sent to /convert is a single file, it doesn't need more */
+const MAX_CONVERT_SIZE = 1 << 20
+
+// Generates schemas when they are asked for, instead of writing files.
+// Packages are only read again when a file in one of them changes.
+type server struct {
+	r    runner
+	jobs []Job
+
+	/* The package cache can't be shared by requests at the same time */
+	mutex sync.Mutex
+
+	cache *gobridge.PackageCache
+
+	// Every package in the cache, and its files when they were read.
+	packageDirs []string
+	snapshot    dirSnapshot
+}
+
+func newServer(r runner, jobs []Job) *server {
+	return &server{r: r, jobs: jobs}
+}
+
+// The cache, or a new one when a package in it has changed.
+func (s *server) getCache() *gobridge.PackageCache {
+	if s.cache != nil && maps.Equal(snapshotDirs(s.r.getDirs(s.packageDirs)), s.snapshot) {
+		return s.cache
+	}
+
+	s.cache = gobridge.NewPackageCache(s.r.rootDir, s.r.modules)
+	s.packageDirs = nil
+	s.snapshot = dirSnapshot{}
+
+	return s.cache
+}
+
+func (s *server) addPackages(packageDirs []string) {
+	for _, dir := range packageDirs {
+		if !slices.Contains(s.packageDirs, dir) {
+			s.packageDirs = append(s.packageDirs, dir)
+		}
+	}
+
+	s.snapshot = snapshotDirs(s.r.getDirs(s.packageDirs))
+}
+
+// The job named by the request, or the first one.
+// Its target and types can be changed by the request too.
+func (s *server) getJob(request *http.Request) (Job, error) {
+	query := request.URL.Query()
+
+	index := 0
+	if query.Has("job") {
+		index = slices.IndexFunc(s.jobs, func(job Job) bool {
+			return job.Name == query.Get("job")
+		})
+
+		if index == -1 {
+			return Job{}, errors.New("Unknown job " + query.Get("job"))
+		}
+	}
+
+	job := s.jobs[index]
+
+	if query.Has("target") {
+		job.Target = query.Get("target")
+		job.Template = ""
+	}
+
+	if query.Has("type") {
+		types := make(listFlag, 0)
+		for _, value := range query["type"] {
+			types.Set(value)
+		}
+
+		job.Types = types
+	}
+
+	return job, nil
+}
+
+// Problems with the request are 400s, and problems with the go code are 422s,
+// so a build can tell whether it should retry after the code is fixed.
+func writeError(writer http.ResponseWriter, status int, err error) {
+	fmt.Fprintln(os.Stderr, "error: "+err.Error())
+	http.Error(writer, err.Error(), status)
+}
+
+func (s *server) handleSchemas(writer http.ResponseWriter, request *http.Request) {
+	job, err := s.getJob(request)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	options, err := job.options()
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	//
+	// The lock file is only read, as whoever uses the schemas
+	// could be looking at numbers that were never written.
+	//
+	options.FieldNumbers, err = job.readLock()
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	diagnostics := make([]error, 0)
+	options.Warn = func(message string) {
+		fmt.Fprintln(os.Stderr, "warning: "+message)
+	}
+
+	options.Error = func(err error) {
+		diagnostics = append(diagnostics, err)
+		printError(err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	output, packageDirs, err := s.r.parseJob(s.getCache(), job, options)
+	if err != nil {
+		//
+		// The cache could have some of the packages that failed,
+		// the next request starts again.
+		//
+		s.cache = nil
+		writeError(writer, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	s.addPackages(packageDirs)
+
+	if job.Strict && len(diagnostics) > 0 {
+		writeError(writer, http.StatusUnprocessableEntity, diagnostics[0])
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(writer, output)
+}
+
+// Converts the go code in the body, like the playground.
+func (s *server) handleConvert(writer http.ResponseWriter, request *http.Request) {
+	code, err := io.ReadAll(http.MaxBytesReader(writer, request.Body, MAX_CONVERT_SIZE))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	target := request.URL.Query().Get("target")
+	if target == "" {
+		target = gobridge.VALIBOT_TARGET
+	}
+
+	structs, err := gobridge.LoadCode(string(code), gobridge.Options{})
+	if err != nil {
+		writeError(writer, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	output, err := gobridge.Generate(target, structs, gobridge.Options{})
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(writer, strings.TrimPrefix(output, "\n"))
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /schemas.ts", s.handleSchemas)
+	mux.HandleFunc("POST /convert", s.handleConvert)
+
+	return mux
+}
+
+func runServe(args []string) int {
+	c, _ := getCommand("serve")
+	flags := newFlagSet(c)
+	f := addJobFlags(flags)
+	addr := flags.String("addr", ":8080", "The address to listen on")
+
+	exitCode, ok := parseFlags(flags, args)
+	if !ok {
+		return exitCode
+	}
+
+	jobs, r, err := f.getJobs(flags, flags.Arg(0), "")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: "+err.Error())
+		return EXIT_USAGE_ERROR
+	}
+
+	fmt.Fprintln(os.Stderr, "serving on "+*addr)
+
+	err = http.ListenAndServe(*addr, newServer(r, jobs).handler())
+	fmt.Fprintln(os.Stderr, "error: "+err.Error())
+	return EXIT_USAGE_ERROR
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func request(t *testing.T, handler http.Handler, method string, url string, body string) (int, string) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, url, strings.NewReader(body)))
+
+	response, err := io.ReadAll(recorder.Result().Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return recorder.Code, string(response)
+}
+
+func TestServeSchemas(t *testing.T) {
+	rootDir := t.TempDir()
+	modelsPath := filepath.Join(rootDir, "models", "models.go")
+
+	writeModels := func(content string) {
+		err := os.MkdirAll(filepath.Dir(modelsPath), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(modelsPath, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeModels("package models\n\ntype User struct {\n  ID string\n}\n\ntype Order struct {\n  ID int\n}\n")
+
+	r := runner{rootDir: rootDir, modules: map[string]string{"example.com/app": "."}}
+	handler := newServer(r, []Job{{Name: "models", Entry: []string{"models"}}}).handler()
+
+	status, body := request(t, handler, "GET", "/schemas.ts?type=User", "")
+	if status != http.StatusOK || !strings.Contains(body, "const User = object({\n  ID: string(),\n});") || strings.Contains(body, "Order") {
+		t.Log(status, body)
+		t.FailNow()
+	}
+
+	//
+	// The package changed, so it is read again.
+	//
+	writeModels("package models\n\ntype User struct {\n  ID   string\n  Name string\n}\n")
+
+	status, body = request(t, handler, "GET", "/schemas.ts?job=models", "")
+	if status != http.StatusOK || !strings.Contains(body, "  Name: string(),") {
+		t.Log(status, body)
+		t.FailNow()
+	}
+
+	status, _ = request(t, handler, "GET", "/schemas.ts?target=typebox", "")
+	if status != http.StatusBadRequest {
+		t.Log(status)
+		t.FailNow()
+	}
+
+	status, _ = request(t, handler, "GET", "/schemas.ts?type=Missing", "")
+	if status != http.StatusUnprocessableEntity {
+		t.Log(status)
+		t.FailNow()
+	}
+}
+
+func TestServeConvert(t *testing.T) {
+	handler := newServer(runner{}, []Job{{}}).handler()
+
+	status, body := request(t, handler, "POST", "/convert", "package types\n\ntype A struct {\n  B bool\n}\n")
+	if status != http.StatusOK || body != "import { object, boolean } from 'valibot';\n\nconst A = object({\n  B: boolean(),\n});\n" {
+		t.Log(status, body)
+		t.FailNow()
+	}
+
+	status, _ = request(t, handler, "POST", "/convert", "package types\n\ntype A struct {")
+	if status != http.StatusUnprocessableEntity {
+		t.Log(status)
+		t.FailNow()
+	}
+
+	status, _ = request(t, handler, "GET", "/convert", "")
+	if status != http.StatusMethodNotAllowed {
+		t.Log(status)
+		t.FailNow()
+	}
+}
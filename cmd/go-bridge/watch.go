@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	/* How often the go files are checked for changes */
+	WATCH_INTERVAL = 500 * time.Millisecond
+
+	// Editors and `git checkout` change several files at once,
+	// so we wait for them to stop before regenerating.
+	WATCH_DEBOUNCE = 200 * time.Millisecond
+)
+
+type fileState struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// Map: file path -> when it last changed.
+// Polling avoids depending on platform specific file notifications.
+type dirSnapshot map[string]fileState
+
+// Missing directories and files are left out, so removing them is a change.
+func snapshotDirs(dirs []string) dirSnapshot {
+	snapshot := make(dirSnapshot)
+
+	for _, dir := range dirs {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, file := range files {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), ".go") {
+				continue
+			}
+
+			info, err := file.Info()
+			if err != nil {
+				continue
+			}
+
+			snapshot[filepath.Join(dir, file.Name())] = fileState{ModTime: info.ModTime(), Size: info.Size()}
+		}
+	}
+
+	return snapshot
+}
+
+// Runs forever, calling `generate` when the packages change.
+// `generate` returns the packages it used, so new imports are watched too.
+// When it fails it returns nil, and we keep watching the same packages.
+func watchDirs(dirs []string, generate func() []string) {
+	fmt.Fprintf(os.Stderr, "watching %d packages\n", len(dirs))
+
+	snapshot := snapshotDirs(dirs)
+
+	for {
+		time.Sleep(WATCH_INTERVAL)
+
+		current := snapshotDirs(dirs)
+		if maps.Equal(current, snapshot) {
+			continue
+		}
+
+		for {
+			time.Sleep(WATCH_DEBOUNCE)
+
+			next := snapshotDirs(dirs)
+			if maps.Equal(next, current) {
+				break
+			}
+
+			current = next
+		}
+
+		newDirs := generate()
+		if newDirs != nil && !slices.Equal(newDirs, dirs) {
+			dirs = newDirs
+			fmt.Fprintf(os.Stderr, "watching %d packages\n", len(dirs))
+		}
+
+		snapshot = snapshotDirs(dirs)
+	}
+}
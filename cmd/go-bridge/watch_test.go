@@ -0,0 +1,48 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotDirs(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "schemas.ts"), []byte("const A = object({});\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := snapshotDirs([]string{dir, filepath.Join(dir, "missing")})
+	if len(snapshot) != 1 {
+		t.Log("Only go files should be watched", snapshot)
+		t.FailNow()
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "schemas.ts"), []byte("const B = object({});\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !maps.Equal(snapshot, snapshotDirs([]string{dir})) {
+		t.Log("Changing the output should not be a change")
+		t.FailNow()
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n\ntype A struct{}\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if maps.Equal(snapshot, snapshotDirs([]string{dir})) {
+		t.Log("Changing a go file should be a change")
+		t.FailNow()
+	}
+}
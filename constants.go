@@ -0,0 +1,219 @@
+package gobridge
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/token"
+	"go/types"
+)
+
+// A constant declared in a package, such as `const Size = 32`.
+// Array lengths can be any constant expression, so we need them.
+type NamedConstant struct {
+	// Constants without a value in a const block repeat
+	// the value of the one above, with their own iota.
+	Value ast.Expr
+	Iota  int
+
+	File        *ast.File
+	PackagePath string
+}
+
+// Map: PackagePath-ConstantName -> NamedConstant
+type NamedConstants = map[string]NamedConstant
+
+func (p *Parser) consumeConstants(file *ast.File, packagePath string) {
+	for _, dec := range file.Decls {
+		constDec, ok := dec.(*ast.GenDecl)
+		if !ok || constDec.Tok != token.CONST {
+			continue
+		}
+
+		var values []ast.Expr
+
+		for i, spec := range constDec.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			if len(valueSpec.Values) > 0 {
+				values = valueSpec.Values
+			}
+
+			for j, name := range valueSpec.Names {
+				if j >= len(values) {
+					break
+				}
+
+				p.constants[packagePath+"-"+name.Name] = NamedConstant{
+					Value:       values[j],
+					Iota:        i,
+					File:        file,
+					PackagePath: packagePath,
+				}
+			}
+		}
+	}
+}
+
+func (p *Parser) importExternal(importPath string) (*types.Package, error) {
+	if p.importer == nil {
+		p.importer = importer.ForCompiler(p.fileSet, "source", nil)
+	}
+
+	return p.importer.Import(importPath)
+}
+
+// Constants of packages outside the project, such as `sha256.Size`,
+// are found by type checking the package from its source.
+func (p *Parser) getExternalConstant(importPath string, name string) (constant.Value, error) {
+	pkg, err := p.importExternal(importPath)
+	if err != nil {
+		return nil, err
+	}
+
+	namedConstant, ok := pkg.Scope().Lookup(name).(*types.Const)
+	if !ok {
+		return nil, unsupportedError("%s is not a constant in %s.", name, importPath)
+	}
+
+	return namedConstant.Val(), nil
+}
+
+func (p *Parser) getNamedConstant(packagePath string, name string) (constant.Value, error) {
+	namespacedName := packagePath + "-" + name
+
+	namedConstant, exists := p.constants[namespacedName]
+	if !exists {
+		return nil, unsupportedError("Could not find the constant %s.", name)
+	}
+
+	if p.resolving[namespacedName] {
+		return nil, unsupportedError("The constant %s is defined by itself.", name)
+	}
+
+	p.resolving[namespacedName] = true
+	defer delete(p.resolving, namespacedName)
+
+	return p.evalConstant(namedConstant.File, namedConstant.PackagePath, namedConstant.Iota, namedConstant.Value)
+}
+
+func (p *Parser) evalSelectorConstant(file *ast.File, packagePath string, expr *ast.SelectorExpr) (constant.Value, error) {
+	depPath, isLocal, err := p.resolveSelectorPackage(OrderedStructType{File: file, PackagePath: packagePath}, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	if isLocal {
+		return p.getNamedConstant(depPath, expr.Sel.Name)
+	}
+
+	packageName := expr.X.(*ast.Ident).Name
+	for _, importSpec := range file.Imports {
+		if getImportName(importSpec) == packageName {
+			return p.getExternalConstant(stripString(importSpec.Path.Value), expr.Sel.Name)
+		}
+	}
+
+	return nil, unsupportedError("Could not find imported package %s.", packageName)
+}
+
+// Evaluates a constant expression, as go would for an array length.
+func (p *Parser) evalConstant(file *ast.File, packagePath string, iota int, expr ast.Expr) (constant.Value, error) {
+	switch t := expr.(type) {
+	case *ast.BasicLit:
+		value := constant.MakeFromLiteral(t.Value, t.Kind, 0)
+		if value.Kind() == constant.Unknown {
+			return nil, unsupportedError("Could not read the constant %s.", t.Value)
+		}
+
+		return value, nil
+	case *ast.Ident:
+		switch t.Name {
+		case "iota":
+			return constant.MakeInt64(int64(iota)), nil
+		case "true", "false":
+			return constant.MakeBool(t.Name == "true"), nil
+		}
+
+		return p.getNamedConstant(packagePath, t.Name)
+	case *ast.SelectorExpr:
+		return p.evalSelectorConstant(file, packagePath, t)
+	case *ast.ParenExpr:
+		return p.evalConstant(file, packagePath, iota, t.X)
+	case *ast.UnaryExpr:
+		value, err := p.evalConstant(file, packagePath, iota, t.X)
+		if err != nil {
+			return nil, err
+		}
+
+		return constant.UnaryOp(t.Op, value, 0), nil
+	case *ast.CallExpr:
+		//
+		// Conversions such as `int(Size)` keep the value.
+		//
+		name, isIdent := t.Fun.(*ast.Ident)
+		if !isIdent || len(t.Args) != 1 || !isIntegerType(getBasicTypeName(name.Name)) {
+			return nil, unsupportedError("Only conversions to integers are supported in constants, got %s.", types.ExprString(t))
+		}
+
+		return p.evalConstant(file, packagePath, iota, t.Args[0])
+	case *ast.BinaryExpr:
+		x, err := p.evalConstant(file, packagePath, iota, t.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := p.evalConstant(file, packagePath, iota, t.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		switch t.Op {
+		case token.SHL, token.SHR:
+			shift, ok := constant.Uint64Val(y)
+			if !ok {
+				return nil, unsupportedError("Could not shift by %s.", y)
+			}
+
+			return constant.Shift(x, t.Op, uint(shift)), nil
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return constant.MakeBool(constant.Compare(x, t.Op, y)), nil
+		case token.QUO:
+			// Dividing integers truncates, like it does in go.
+			if x.Kind() == constant.Int && y.Kind() == constant.Int {
+				if constant.Sign(y) == 0 {
+					return nil, unsupportedError("Division by zero in %s.", types.ExprString(t))
+				}
+
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y), nil
+			}
+		}
+
+		return constant.BinaryOp(x, t.Op, y), nil
+	default:
+		return nil, unsupportedError("Only constant expressions are supported as array lengths, got %s.", types.ExprString(expr))
+	}
+}
+
+func (p *Parser) parseArrayLength(orderedStruct OrderedStructType, lengthExpr ast.Expr) (length int, err error) {
+	//
+	// go/constant panics on operations go wouldn't compile,
+	// such as adding a string to a number.
+	//
+	defer func() {
+		if recover() != nil {
+			length, err = 0, unsupportedError("%s is not a valid array length.", types.ExprString(lengthExpr))
+		}
+	}()
+
+	value, err := p.evalConstant(orderedStruct.File, orderedStruct.PackagePath, 0, lengthExpr)
+	if err != nil {
+		return 0, err
+	}
+
+	value64, ok := constant.Int64Val(constant.ToInt(value))
+	if !ok || value64 < 0 {
+		return 0, unsupportedError("%s is not a valid array length.", value.ExactString())
+	}
+
+	return int(value64), nil
+}
@@ -0,0 +1,46 @@
+package gobridge
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Returned for valid go code that we can't generate a schema for,
+// as opposed to code we couldn't read or parse.
+type UnsupportedError struct {
+	Message string
+}
+
+func (e UnsupportedError) Error() string {
+	return e.Message
+}
+
+func unsupportedError(format string, a ...any) error {
+	return UnsupportedError{Message: fmt.Sprintf(format, a...)}
+}
+
+// An error in a struct field, with where it is in the source,
+// printed the same way as the go compiler does.
+type SourceError struct {
+	File   string
+	Line   int
+	Column int
+
+	Struct string
+	Field  string
+
+	Err error
+}
+
+func (e SourceError) Error() string {
+	location := strconv.Itoa(e.Line) + ":" + strconv.Itoa(e.Column)
+	if e.File != "" {
+		location = e.File + ":" + location
+	}
+
+	return location + ": " + e.Err.Error() + " (field " + e.Field + " of struct " + e.Struct + ")"
+}
+
+func (e SourceError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,209 @@
+package gobridge
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// `PackagePath-Name` the way people write it, `PackagePath.Name`.
+func getDisplayName(namespacedName string) string {
+	return strings.Replace(namespacedName, "-", ".", 1)
+}
+
+// Finds a struct by its name, or by its package path and name
+// when several packages have a struct with that name.
+func findStruct(structList StructList, typeName string) (Struct, error) {
+	matches := make([]Struct, 0)
+
+	for _, s := range structList {
+		if getName(s.Name) == typeName || getDisplayName(s.Name) == typeName {
+			matches = append(matches, s)
+		}
+	}
+
+	if len(matches) == 0 {
+		return Struct{}, errors.New("Could not find struct " + typeName)
+	}
+
+	if len(matches) > 1 {
+		names := make([]string, 0, len(matches))
+		for _, s := range matches {
+			names = append(names, getDisplayName(s.Name))
+		}
+
+		return Struct{}, errors.New(typeName + " could be any of " + strings.Join(names, ", "))
+	}
+
+	return matches[0], nil
+}
+
+// Why a field ends up with its schema.
+func explainField(field StructField, nameMap map[string]string, options Options) string {
+	switch t := field.(type) {
+	case BasicStructField:
+		_, err := getJsType(t.Type)
+		if err == NoJsType {
+			return "the struct " + getDisplayName(t.Type) + ", generated as " + nameMap[t.Type]
+		}
+
+		reason := "the go type " + t.Type
+		if hasJsonOption(t.StructTag, "string") {
+			reason += `, which json:",string" encodes as a string`
+		}
+
+		if isInt64Type(t.Type) {
+			mode := options.Int64
+			if mode == "" {
+				mode = Int64AsNumber
+			}
+
+			reason += ", as a " + string(mode) + " (-int64)"
+		}
+
+		if options.IntegerChecks && isIntegerType(t.Type) {
+			reason += ", checked to be within its range (-int-checks)"
+		}
+
+		return reason
+	case UnknownStructField:
+		if _, hasOverride := options.Overrides[t.FullType]; hasOverride {
+			return t.FullType + " has an override"
+		}
+
+		switch t.Encoding {
+		case MARSHAL_JSON:
+			return t.FullType + " has a MarshalJSON method, so its fields say nothing about its JSON. Add an override to describe it"
+		case MARSHAL_TEXT:
+			return t.FullType + " has a MarshalText method, so it is a string. Add an override to describe its format"
+		}
+
+		if t.FullType == ANY_TYPE {
+			return "an interface without a gobridge:union directive, or any, so it could be anything"
+		}
+
+		return t.FullType + " is not part of this project, add an override to describe it"
+	case UnionStructField:
+		variants := make([]string, 0, len(t.Variants))
+		for _, variant := range t.Variants {
+			variants = append(variants, getDisplayName(variant.Type)+" when "+t.Discriminator+" is "+strconv.Quote(variant.Value))
+		}
+
+		return "an interface with a gobridge:union directive: " + strings.Join(variants, ", ")
+	case ArrayStructField:
+		element := explainField(t.Type, nameMap, options)
+
+		if isByteSlice(t) {
+			return "a byte slice, which encoding/json writes as a base64 string"
+		}
+
+		if !t.Fixed {
+			return "a slice of " + element
+		}
+
+		if t.Length <= MAX_TUPLE_LENGTH {
+			return "an array of " + strconv.Itoa(t.Length) + ", as a tuple of " + element
+		}
+
+		return "an array of " + strconv.Itoa(t.Length) + ", longer than a tuple of " + strconv.Itoa(MAX_TUPLE_LENGTH) + ", of " + element
+	case MapStructField:
+		reason := "a map with " + t.KeyType + " keys"
+		if isIntegerType(t.KeyType) {
+			reason += ", which encoding/json writes as strings"
+		}
+
+		return reason + ", of " + explainField(t.Value, nameMap, options)
+	case AnonStructField:
+		return "an anonymous struct"
+	default:
+		return "unknown field"
+	}
+}
+
+// Shows the schema of a struct, and why each of its fields has its schema.
+func ExplainStruct(structList StructList, typeName string, options Options) (string, error) {
+	s, err := findStruct(structList, typeName)
+	if err != nil {
+		return "", err
+	}
+
+	nameMap := getNameMap(structList, options)
+
+	var counter uint = 0
+	validators := make(map[string]uint)
+
+	output := getDisplayName(s.Name) + " is generated as " + nameMap[s.Name] + "\n"
+
+	for _, field := range s.Fields {
+		schema, err := getStructFieldType(validators, nameMap, &counter, field, 1, options)
+		if err != nil {
+			return "", err
+		}
+
+		output += "\n  " + getObjectKey(field) + ": " + schema + "\n"
+		output += "    " + field.Name() + " is " + explainField(field, nameMap, options) + "\n"
+	}
+
+	return output, nil
+}
+
+// Every struct, under the package it is from.
+func ListStructs(structList StructList) string {
+	packages := make(map[string][]string)
+	packagePaths := make([]string, 0)
+
+	for _, s := range structList {
+		if _, exists := packages[s.PackagePath]; !exists {
+			packagePaths = append(packagePaths, s.PackagePath)
+		}
+
+		packages[s.PackagePath] = append(packages[s.PackagePath], getName(s.Name))
+	}
+
+	sort.Strings(packagePaths)
+
+	output := ""
+	for _, packagePath := range packagePaths {
+		output += packagePath + "\n"
+		for _, name := range packages[packagePath] {
+			output += "  " + name + "\n"
+		}
+	}
+
+	return output
+}
+
+// One `A -> B` line for every struct A uses, or a graphviz digraph.
+// Structs that don't use any are on their own line.
+func GraphStructs(structList StructList, dot bool) string {
+	lines := make([]string, 0)
+
+	for _, s := range structList {
+		dependencies := getStructDependencies(s)
+
+		name := getDisplayName(s.Name)
+		if dot {
+			name = strconv.Quote(name)
+		}
+
+		if len(dependencies) == 0 {
+			lines = append(lines, name)
+		}
+
+		for _, dependency := range dependencies {
+			dependencyName := getDisplayName(dependency)
+			if dot {
+				dependencyName = strconv.Quote(dependencyName)
+			}
+
+			lines = append(lines, name+" -> "+dependencyName)
+		}
+	}
+
+	if !dot {
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	return "digraph gobridge {\n  " + strings.Join(lines, ";\n  ") + ";\n}\n"
+}
@@ -0,0 +1,105 @@
+package gobridge
+
+import (
+	"go/parser"
+	"testing"
+)
+
+func getCodeStructs(t *testing.T, content string) StructList {
+	p := newParser("")
+
+	astFile, err := parser.ParseFile(p.fileSet, "", content, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.consumeFile(astFile, astFile.Name.Name)
+	p.queuePackage(p.packages[astFile.Name.Name])
+
+	structs, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	structs, err = OrderStructs(structs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return structs
+}
+
+const explainCode = `
+package types
+
+type Event interface{}
+
+type User struct {
+  ID      int64             ` + "`json:\"id,string\"`" + `
+  Friends []Friend
+  Scores  map[int]float64
+  Data    any
+}
+
+type Friend struct {
+  Name string
+}
+`
+
+func TestExplainStruct(t *testing.T) {
+	structs := getCodeStructs(t, explainCode)
+
+	explanation, err := ExplainStruct(structs, "User", Options{Int64: Int64AsString})
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	expected := `types.User is generated as User
+
+  id: pipe(string(), regex(/^-?\d+$/))
+    ID is the go type int64, which json:",string" encodes as a string, as a string (-int64)
+
+  Friends: array(Friend)
+    Friends is a slice of the struct types.Friend, generated as Friend
+
+  Scores: record(pipe(string(), regex(/^-?\d+$/)), number())
+    Scores is a map with int keys, which encoding/json writes as strings, of the go type float64
+
+  Data: unknown()
+    Data is an interface without a gobridge:union directive, or any, so it could be anything
+`
+
+	if explanation != expected {
+		t.Log(explanation)
+		t.FailNow()
+	}
+
+	_, err = ExplainStruct(structs, "Missing", Options{})
+	if err == nil {
+		t.Log("Missing structs should fail")
+		t.FailNow()
+	}
+}
+
+func TestListAndGraph(t *testing.T) {
+	structs := getCodeStructs(t, explainCode)
+
+	list := ListStructs(structs)
+	if list != "types\n  Friend\n  User\n" {
+		t.Log(list)
+		t.FailNow()
+	}
+
+	graph := GraphStructs(structs, false)
+	if graph != "types.Friend\ntypes.User -> types.Friend\n" {
+		t.Log(graph)
+		t.FailNow()
+	}
+
+	dot := GraphStructs(structs, true)
+	if dot != "digraph gobridge {\n  \"types.Friend\";\n  \"types.User\" -> \"types.Friend\";\n}\n" {
+		t.Log(dot)
+		t.FailNow()
+	}
+}
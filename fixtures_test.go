@@ -0,0 +1,70 @@
+package gobridge
+
+// Used by the targets that write every kind of field.
+const modelsCode = `
+package types
+
+// Somebody using the app.
+type User struct {
+  ID      uint8             ` + "`json:\"id\"`" + `
+  Name    string            ` + "`json:\"name,omitempty\"`" + `
+  // Who they follow.
+  Friends []Friend          ` + "`json:\"friends\"`" + `
+  Scores  map[int]float64   ` + "`json:\"scores\"`" + `
+  From    [2]string         ` + "`json:\"from\"`" + `
+  Avatar  []byte            ` + "`json:\"avatar\"`" + `
+  Extra   interface{}       ` + "`json:\"extra\"`" + `
+  Address struct {
+    Street string ` + "`json:\"street\"`" + `
+  } ` + "`json:\"address\"`" + `
+}
+
+type Friend struct {
+  Best *Friend
+  Pet  Pet ` + "`json:\"pet\"`" + `
+}
+
+//gobridge:union kind Dog=dog Cat=cat
+type Pet interface{}
+
+type Dog struct {
+  Barks bool ` + "`json:\"barks\"`" + `
+}
+
+type Cat struct{}
+`
+
+// Used by the targets with their own discriminated unions.
+const unionCode = `
+package types
+
+type Circle struct {
+  Radius float64 ` + "`json:\"radius\"`" + `
+}
+
+type Square struct {
+  Side float64 ` + "`json:\"side\"`" + `
+}
+
+//gobridge:union type Circle=circle Square=square
+type Shape interface{}
+
+type Drawing struct {
+  Main   Shape   ` + "`json:\"main\"`" + `
+  Shapes []Shape ` + "`json:\"shapes\"`" + `
+}
+`
+
+// Node and Tree use each other, and Node uses itself.
+const cycleCode = `
+package tree
+
+type Node struct {
+  Children []Node
+  Parent   *Tree
+}
+
+type Tree struct {
+  Root Node
+}
+`
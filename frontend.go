@@ -1,4 +1,4 @@
-package main
+package gobridge
 
 import (
 	"errors"
@@ -6,12 +6,17 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-const EMBEDDED_DEP = "SUPER SECRET STRING FOR EMBEDDED DEPS"
+const UNION_DIRECTIVE = "//gobridge:union "
 
 type OrderedStructType struct {
 	*ast.StructType
@@ -20,34 +25,351 @@ type OrderedStructType struct {
 	StructName  string
 	PackagePath string
 	Order       uint
+
+	/* The comment above the struct, and where it is declared */
+	Doc string
+	Pos token.Pos
 }
 
 type NameToStructPos = map[string]OrderedStructType
 
-// Map: ModuleName -> List of its structs
-type ModuleStructs = map[string]NameToStructPos
+// Named types that are not structs, such as `type ID int`.
+// We need them to resolve what a type is encoded as.
+type NamedType struct {
+	Type ast.Expr
+	File *ast.File
+	Doc  *ast.CommentGroup
 
-// ====================== TODO ======================
-// `moduleStructs` now is too complicated, because
-// we are using the full paths to the packages as
-// we know we can't have duplicated full paths.
-//
-// We could flatten this map,
-// and make our code a bit nicer.
-// ==================================================
+	PackagePath string
+}
+
+// Map: PackagePath-TypeName -> NamedType
+type NamedTypes = map[string]NamedType
+
+// A loaded package, with every struct declared in it.
+// Only the structs we need make it into the output.
+type Package struct {
+	Name string
+	Path string
+
+	Structs NameToStructPos
+
+	/* Structs are ordered across all files of the package */
+	order uint
+}
+
+// Map: PackagePath -> Package
+type Packages = map[string]*Package
+
+// Map: module path -> its directory, relative to the project root
+type Modules = map[string]string
+
+// Everything we know about the packages we have read,
+// which can be shared by several parsers so each package is only read once.
+type PackageCache struct {
+	// The directory of go.mod, or go.work in a workspace.
+	// Package paths are relative to it.
+	rootDir string
+
+	/* Modules whose packages are part of the project */
+	modules Modules
+
+	/* Shared by every file, so errors can point to where they happened */
+	fileSet *token.FileSet
+
+	packages   Packages
+	namedTypes NamedTypes
+	constants  NamedConstants
+
+	/* Reads packages outside the project, only for their constants */
+	importer types.Importer
+
+	// Map: PackagePath-TypeName -> MARSHAL_JSON or MARSHAL_TEXT
+	// Types with their own MarshalJSON or MarshalText methods.
+	marshalers map[string]string
+}
+
+// rootDir is the directory of go.mod, or of go.work in a workspace,
+// and modules are the modules found there, see LoadModules.
+func NewPackageCache(rootDir string, modules Modules) *PackageCache {
+	return &PackageCache{
+		rootDir:    rootDir,
+		modules:    modules,
+		fileSet:    token.NewFileSet(),
+		packages:   make(Packages),
+		namedTypes: make(NamedTypes),
+		constants:  make(NamedConstants),
+		marshalers: make(map[string]string),
+	}
+}
 
 type Parser struct {
-	projectPath  string
+	*PackageCache
+
 	entryPackage string
 
-	moduleStructs ModuleStructs
-	outputStructs []Struct
+	/* When set, field errors are reported here instead of stopping the parser */
+	report func(err error)
+
+	/* Map: PackageName.TypeName -> union directive, see Options.Unions */
+	unions map[string]string
+
+	/* Packages this parser has used, which can be fewer than the cache has */
+	loaded map[string]bool
+
+	// Structs waiting to be parsed,
+	// and every struct that has ever been queued.
+	queue  []OrderedStructType
+	queued map[string]bool
+
+	/* Named types being resolved, to catch recursive types */
+	resolving map[string]bool
+}
+
+func newParser(projectPath string) Parser {
+	modules := make(Modules)
+	if projectPath != "" {
+		modules[projectPath] = "."
+	}
+
+	return newParserWithCache(NewPackageCache(".", modules))
+}
+
+func newParserWithCache(cache *PackageCache) Parser {
+	return Parser{
+		PackageCache: cache,
+		loaded:       make(map[string]bool),
+		queue:        make([]OrderedStructType, 0),
+		queued:       make(map[string]bool),
+		resolving:    make(map[string]bool),
+	}
+}
+
+func (p *Parser) consumeNamedTypes(file *ast.File, packagePath string) {
+	for _, dec := range file.Decls {
+		typeDec, ok := dec.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+
+		for _, spec := range typeDec.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+
+			if _, isStruct := typeSpec.Type.(*ast.StructType); isStruct {
+				continue
+			}
+
+			// Without parenthesis, the comment belongs to the declaration.
+			doc := typeSpec.Doc
+			if doc == nil && len(typeDec.Specs) == 1 {
+				doc = typeDec.Doc
+			}
+
+			p.namedTypes[packagePath+"-"+typeSpec.Name.Name] = NamedType{
+				Type:        typeSpec.Type,
+				File:        file,
+				Doc:         doc,
+				PackagePath: packagePath,
+			}
+		}
+	}
+}
+
+func getReceiverName(receiver ast.Expr) (string, bool) {
+	switch t := receiver.(type) {
+	case *ast.StarExpr:
+		return getReceiverName(t.X)
+	case *ast.IndexExpr:
+		return getReceiverName(t.X)
+	case *ast.IndexListExpr:
+		return getReceiverName(t.X)
+	case *ast.Ident:
+		return t.Name, true
+	default:
+		return "", false
+	}
+}
+
+// Finds the types whose JSON encoding has nothing to do with their fields,
+// because they implement json.Marshaler or encoding.TextMarshaler.
+func (p *Parser) consumeMethods(file *ast.File, packagePath string) {
+	for _, dec := range file.Decls {
+		funcDec, ok := dec.(*ast.FuncDecl)
+		if !ok || funcDec.Recv == nil || len(funcDec.Recv.List) == 0 {
+			continue
+		}
+
+		encoding := ""
+		switch funcDec.Name.Name {
+		case "MarshalJSON":
+			encoding = MARSHAL_JSON
+		case "MarshalText":
+			encoding = MARSHAL_TEXT
+		default:
+			continue
+		}
+
+		receiverName, ok := getReceiverName(funcDec.Recv.List[0].Type)
+		if !ok {
+			continue
+		}
+
+		namespacedName := packagePath + "-" + receiverName
+
+		// Like encoding/json, MarshalJSON wins over MarshalText.
+		if p.marshalers[namespacedName] == MARSHAL_JSON {
+			continue
+		}
+
+		p.marshalers[namespacedName] = encoding
+	}
+}
+
+// encoding/json also uses methods promoted from embedded fields,
+// so a struct embedding a marshaler, such as `time.Time`, is encoded by it.
+//
+// Returns MARSHAL_JSON, MARSHAL_TEXT or "" when the type has neither.
+func (p *Parser) getMarshaler(packagePath string, typeName string) string {
+	namespacedName := packagePath + "-" + typeName
+
+	encoding, isMarshaler := p.marshalers[namespacedName]
+	if isMarshaler {
+		return encoding
+	}
+
+	pkg, exists := p.packages[packagePath]
+	if !exists {
+		return ""
+	}
+
+	s, exists := pkg.Structs[namespacedName]
+	if !exists || p.resolving[namespacedName] {
+		return ""
+	}
+
+	p.resolving[namespacedName] = true
+	defer delete(p.resolving, namespacedName)
+
+	encoding = p.getEmbeddedMarshaler(s)
+	if encoding != "" {
+		p.marshalers[namespacedName] = encoding
+	}
+
+	return encoding
+}
+
+func (p *Parser) getEmbeddedMarshaler(s OrderedStructType) string {
+	encoding := ""
+
+	for _, field := range s.StructType.Fields.List {
+		if len(field.Names) > 0 {
+			continue
+		}
+
+		fieldType := field.Type
+		if star, ok := fieldType.(*ast.StarExpr); ok {
+			fieldType = star.X
+		}
+
+		embedded := ""
+		switch t := fieldType.(type) {
+		case *ast.Ident:
+			embedded = p.getMarshaler(s.PackagePath, t.Name)
+		case *ast.SelectorExpr:
+			embedded = p.getSelectorMarshaler(s, t)
+		}
+
+		// Like encoding/json, MarshalJSON wins over MarshalText.
+		if embedded == MARSHAL_JSON {
+			return embedded
+		}
+
+		if embedded != "" {
+			encoding = embedded
+		}
+	}
+
+	return encoding
+}
+
+func (p *Parser) getSelectorMarshaler(s OrderedStructType, expr *ast.SelectorExpr) string {
+	depPath, isLocal, err := p.resolveSelectorPackage(s, expr)
+	if err != nil {
+		return ""
+	}
+
+	if isLocal {
+		return p.getMarshaler(depPath, expr.Sel.Name)
+	}
+
+	packageName := expr.X.(*ast.Ident).Name
+	for _, importSpec := range s.File.Imports {
+		if getImportName(importSpec) == packageName {
+			return p.getExternalMarshaler(stripString(importSpec.Path.Value), expr.Sel.Name)
+		}
+	}
+
+	return ""
+}
+
+// Types of packages outside the project are type checked from their source,
+// like their constants. Without the source, such as in the browser, we can't tell.
+func (p *Parser) getExternalMarshaler(importPath string, typeName string) string {
+	pkg, err := p.importExternal(importPath)
+	if err != nil {
+		return ""
+	}
+
+	namedType, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return ""
+	}
+
+	methods := types.NewMethodSet(types.NewPointer(namedType.Type()))
+
+	if methods.Lookup(pkg, "MarshalJSON") != nil {
+		return MARSHAL_JSON
+	}
+
+	if methods.Lookup(pkg, "MarshalText") != nil {
+		return MARSHAL_TEXT
+	}
+
+	return ""
+}
+
+// Types implementing a marshaler are unknown, we can't look inside their methods.
+func (p *Parser) getMarshalerField(packagePath string, typeName string, fieldName string) (StructField, bool) {
+	encoding := p.getMarshaler(packagePath, typeName)
+	if encoding == "" {
+		return nil, false
+	}
+
+	return UnknownStructField{
+		FieldName: fieldName,
+		FullType:  p.packages[packagePath].Name + "." + typeName,
+		Encoding:  encoding,
+	}, true
 }
 
 func (p *Parser) consumeFile(file *ast.File, packagePath string) string {
-	allStructs := make(NameToStructPos)
+	p.consumeNamedTypes(file, packagePath)
+	p.consumeConstants(file, packagePath)
+	p.consumeMethods(file, packagePath)
 
-	var order uint = 0
+	pkg, exists := p.packages[packagePath]
+	if !exists {
+		pkg = &Package{
+			Name:    file.Name.Name,
+			Path:    packagePath,
+			Structs: make(NameToStructPos),
+		}
+
+		p.packages[packagePath] = pkg
+	}
 
 	for _, dec := range file.Decls {
 		typeDec, ok := dec.(*ast.GenDecl)
@@ -66,39 +388,41 @@ func (p *Parser) consumeFile(file *ast.File, packagePath string) string {
 				continue
 			}
 
-			allStructs[packagePath+"-"+typeSpec.Name.Name] = OrderedStructType{
+			//
+			// `type A struct` has its comment on the declaration,
+			// types in a `type ( ... )` block have their own.
+			//
+			doc := typeSpec.Doc
+			if doc == nil && len(typeDec.Specs) == 1 {
+				doc = typeDec.Doc
+			}
+
+			pkg.Structs[packagePath+"-"+typeSpec.Name.Name] = OrderedStructType{
 				StructType:  structType,
 				StructName:  typeSpec.Name.Name,
-				Order:       order,
+				Order:       pkg.order,
 				PackagePath: packagePath,
 
+				Doc: doc.Text(),
+				Pos: typeSpec.Name.Pos(),
+
 				File: file,
 			}
 
-			order++
+			pkg.order++
 		}
 	}
 
-	existingModuleStructs, exists := p.moduleStructs[packagePath]
-	if !exists {
-		p.moduleStructs[packagePath] = allStructs
-		return file.Name.Name
-	}
-
-	for k, v := range allStructs {
-		existingModuleStructs[k] = v
-	}
-
 	return file.Name.Name
 }
 
-func (p *Parser) consumeDir(dirPath string) (string, error) {
+func parseDir(fileSet *token.FileSet, dirPath string) ([]*ast.File, error) {
 	files, err := os.ReadDir(dirPath)
 	if err != nil {
-		return "", err
+		return []*ast.File{}, err
 	}
 
-	packageName := ""
+	astFiles := make([]*ast.File, 0)
 
 	for _, file := range files {
 		fileName := file.Name()
@@ -106,189 +430,488 @@ func (p *Parser) consumeDir(dirPath string) (string, error) {
 			continue
 		}
 
-		fileContent, err := os.ReadFile(filepath.Join(dirPath, fileName))
+		filePath := filepath.Join(dirPath, fileName)
+
+		fileContent, err := os.ReadFile(filePath)
 		if err != nil {
-			return "", err
+			return []*ast.File{}, err
 		}
 
-		astFile, err := parser.ParseFile(token.NewFileSet(), "", fileContent, 0)
+		astFile, err := parser.ParseFile(fileSet, filePath, fileContent, parser.ParseComments)
 		if err != nil {
-			return "", err
+			return []*ast.File{}, err
 		}
 
-		packageName = astFile.Name.Name
+		astFiles = append(astFiles, astFile)
+	}
+
+	return astFiles, nil
+}
+
+// Loads every declaration of a package, without adding
+// any of its structs to the output.
+func (p *Parser) loadPackage(dirPath string) (*Package, error) {
+	p.loaded[dirPath] = true
+
+	pkg, exists := p.packages[dirPath]
+	if exists {
+		return pkg, nil
+	}
+
+	astFiles, err := parseDir(p.fileSet, filepath.Join(p.rootDir, dirPath))
+	if err != nil {
+		return nil, err
+	}
 
+	for _, astFile := range astFiles {
 		p.consumeFile(astFile, dirPath)
 	}
 
-	return packageName, nil
+	pkg, exists = p.packages[dirPath]
+	if !exists {
+		return nil, errors.New("Could not find any go files in " + dirPath)
+	}
+
+	return pkg, nil
 }
 
-func (p *Parser) parseEmbeddedStructField(orderedStruct OrderedStructType, structName string) ([]StructField, error) {
-	moduleStruct, exists := p.moduleStructs[orderedStruct.File.Name.Name]
-	if !exists {
-		return []StructField{}, errors.New("Could not find package of embedded struct")
+// The directories of every package that was used, in order.
+func (p *Parser) packageDirs() []string {
+	dirs := make([]string, 0, len(p.loaded))
+	for dir := range p.loaded {
+		dirs = append(dirs, dir)
+	}
+
+	sort.Strings(dirs)
+	return dirs
+}
+
+// Adds a struct to the output, it will be parsed by `Parse`.
+func (p *Parser) queueStruct(packagePath string, structName string) error {
+	namespacedName := packagePath + "-" + structName
+	if p.queued[namespacedName] {
+		return nil
 	}
 
-	astF, exists := moduleStruct[orderedStruct.PackagePath+"-"+structName]
+	pkg, exists := p.packages[packagePath]
 	if !exists {
-		return []StructField{}, errors.New("Could not find embedded struct")
+		return errors.New("Package " + packagePath + " has not been loaded")
 	}
 
-	embeddedStructFields, err := p.parseStruct(astF)
-	if err != nil {
-		return []StructField{}, err
+	s, exists := pkg.Structs[namespacedName]
+	if !exists {
+		return errors.New(fmt.Sprintf("Could not find struct %s in package %s", structName, packagePath))
 	}
 
-	return embeddedStructFields, nil
+	p.queued[namespacedName] = true
+	p.queue = append(p.queue, s)
+
+	return nil
 }
 
-func (p *Parser) getPackagePath(imports []*ast.ImportSpec, packageName string) string {
-	for _, i := range imports {
-		v := i.Path.Value
-		if !strings.Contains(v, p.projectPath) {
+func (p *Parser) queuePackage(pkg *Package) {
+	structs := make([]OrderedStructType, 0, len(pkg.Structs))
+	for _, s := range pkg.Structs {
+		structs = append(structs, s)
+	}
+
+	sort.Slice(structs, func(i, j int) bool {
+		return structs[i].Order < structs[j].Order
+	})
+
+	for _, s := range structs {
+		// Their fields would be a misleading schema.
+		if p.getMarshaler(s.PackagePath, s.StructName) != "" {
 			continue
 		}
 
-		v = v[len(p.projectPath)+2 : len(v)-1]
+		p.queueStruct(s.PackagePath, s.StructName)
+	}
+}
 
-		_, lastPath := filepath.Split(v)
+func stripString(s string) string {
+	return s[1 : len(s)-1]
+}
 
-		if lastPath == packageName {
-			return v
-		}
+func getImportName(importSpec *ast.ImportSpec) string {
+	if importSpec.Name != nil {
+		return importSpec.Name.Name
 	}
 
-	return ""
+	return filepath.Base(stripString(importSpec.Path.Value))
 }
 
-func stripString(s string) string {
-	return s[1 : len(s)-1]
+// Finds the directory of an import path, relative to the project root,
+// when it is in one of the local modules. Modules can be nested,
+// so the longest module path wins.
+func (p *Parser) getImportPathDir(importPath string) (string, bool) {
+	modulePath := ""
+	moduleDir := ""
+
+	for candidatePath, candidateDir := range p.modules {
+		if importPath != candidatePath && !strings.HasPrefix(importPath, candidatePath+"/") {
+			continue
+		}
+
+		if len(candidatePath) > len(modulePath) {
+			modulePath = candidatePath
+			moduleDir = candidateDir
+		}
+	}
+
+	if modulePath == "" {
+		return "", false
+	}
+
+	return path.Join(moduleDir, strings.TrimPrefix(importPath, modulePath)), true
 }
 
+// Without any modules, such as with `CodeParse`, nothing is local.
 func (p *Parser) isLocalDependency(imports []*ast.ImportSpec, moduleName string) bool {
 	for _, i := range imports {
-		strippedValue := stripString(i.Path.Value)
-		importBase := filepath.Base(strippedValue)
-		if importBase == moduleName {
-			if len(strippedValue) < len(p.projectPath) {
-				return false
-			}
+		if getImportName(i) == moduleName {
+			_, isLocal := p.getImportPathDir(stripString(i.Path.Value))
+			return isLocal
+		}
+	}
 
-			return strippedValue[0:len(p.projectPath)] == p.projectPath
+	return false
+}
+
+// Returns the directory of a local import, relative to the project root.
+func (p *Parser) getImportDir(imports []*ast.ImportSpec, packageName string) (string, error) {
+	for _, in := range imports {
+		if getImportName(in) == packageName {
+			dir, isLocal := p.getImportPathDir(stripString(in.Path.Value))
+			if isLocal {
+				return dir, nil
+			}
 		}
 	}
 
-	// Potentially float an error.
-	// This can only be triggered by a Go file that would fail dependency check.
-	panic("unreachable")
+	return "", errors.New("Could not find imported package " + packageName)
 }
 
-func (p *Parser) parseDependencyField(orderedStruct OrderedStructType, fieldName string, expr *ast.SelectorExpr) (StructField, error) {
+// Loads the package of a selector such as `nested.Something`.
+//
+// Returns the directory of the package, and false if it isn't part of the project.
+func (p *Parser) resolveSelectorPackage(orderedStruct OrderedStructType, expr *ast.SelectorExpr) (string, bool, error) {
 	packageName, ok := expr.X.(*ast.Ident)
 	if !ok {
-		return BasicStructField{}, errors.New("Could not match type of package")
+		return "", false, errors.New("Could not match type of package")
 	}
 
-	// nested.something
-	// time.Time
-
 	if !p.isLocalDependency(orderedStruct.File.Imports, packageName.Name) {
-		return UnknownStructField{FullType: packageName.Name + "." + expr.Sel.Name, name: fieldName}, nil
+		return "", false, nil
+	}
+
+	depPath, err := p.getImportDir(orderedStruct.File.Imports, packageName.Name)
+	if err != nil {
+		return "", false, err
+	}
+
+	_, err = p.loadPackage(depPath)
+	if err != nil {
+		return "", false, err
+	}
+
+	return depPath, true, nil
+}
+
+// Parses the underlying type of named types, such as `type Cents int64`.
+//
+// Returns false if there is no such named type.
+func (p *Parser) parseNamedType(packagePath string, typeName string, fieldName string) (StructField, bool, error) {
+	namespacedName := packagePath + "-" + typeName
+
+	namedType, exists := p.namedTypes[namespacedName]
+	if !exists {
+		return nil, false, nil
+	}
+
+	if p.resolving[namespacedName] {
+		return nil, true, unsupportedError("Recursive type %s is not supported", typeName)
+	}
+
+	p.resolving[namespacedName] = true
+	defer delete(p.resolving, namespacedName)
+
+	//
+	// Like overrides, a union in the options wins over the directive.
+	//
+	directive, isUnion := getUnionDirective(namedType.Doc)
+	if configured, isConfigured := p.unions[p.packages[packagePath].Name+"."+typeName]; isConfigured {
+		directive, isUnion = configured, true
+	}
+
+	if isUnion {
+		field, err := p.parseUnion(namedType, typeName, fieldName, directive)
+		return field, true, err
 	}
 
-	// ==== TODO: Refactor into seperate function ====
+	namedStruct := OrderedStructType{File: namedType.File, PackagePath: namedType.PackagePath}
+
+	field, err := p.parseStructFieldType(namedStruct, fieldName, namedType.Type)
+	return field, true, err
+}
 
-	depPackagePath := p.getPackagePath(orderedStruct.File.Imports, packageName.Name)
-	structName := ""
-	if depPackagePath != "" {
-		structName = depPackagePath + "-" + expr.Sel.Name
-	} else {
-		structName = orderedStruct.PackagePath + "-" + expr.Sel.Name
+// Interfaces can declare their implementations with a directive,
+//
+//	//gobridge:union type Circle Square=square nested.Triangle
+//	type Shape interface{}
+//
+// The first word is the name of the discriminator field, followed by every
+// struct implementing the interface. By default the discriminator value is the
+// name of the struct, `Struct=value` overrides it.
+func getUnionDirective(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+
+	for _, comment := range doc.List {
+		directive, isUnion := strings.CutPrefix(comment.Text, UNION_DIRECTIVE)
+		if isUnion {
+			return directive, true
+		}
 	}
 
-	// ====
+	return "", false
+}
+
+func (p *Parser) parseUnion(namedType NamedType, typeName string, fieldName string, directive string) (StructField, error) {
+	words := strings.Fields(directive)
+	if len(words) < 2 {
+		return BasicStructField{}, errors.New("gobridge:union on " + typeName + " needs a discriminator and at least one struct")
+	}
 
-	depImport := ""
-	fullPath := ""
+	namedStruct := OrderedStructType{File: namedType.File, PackagePath: namedType.PackagePath}
+	variants := make([]UnionVariant, 0, len(words)-1)
 
-	for _, in := range orderedStruct.File.Imports {
-		strippedValue := in.Path.Value[1 : len(in.Path.Value)-1]
-		importPackageName := filepath.Base(strippedValue)
+	for _, word := range words[1:] {
+		variantName, value, hasValue := strings.Cut(word, "=")
 
-		if importPackageName == packageName.Name {
-			depImport = importPackageName
-			fullPath = strippedValue[len(p.projectPath)+1:]
-			break
+		variantExpr, err := parser.ParseExpr(variantName)
+		if err != nil {
+			return BasicStructField{}, errors.New("Could not parse " + variantName + " in gobridge:union on " + typeName)
+		}
+
+		packagePath := namedStruct.PackagePath
+
+		switch t := variantExpr.(type) {
+		case *ast.Ident:
+			variantName = t.Name
+		case *ast.SelectorExpr:
+			depPath, isLocal, err := p.resolveSelectorPackage(namedStruct, t)
+			if err != nil {
+				return BasicStructField{}, err
+			}
+
+			if !isLocal {
+				return BasicStructField{}, errors.New(variantName + " in gobridge:union on " + typeName + " is not part of this project")
+			}
+
+			packagePath = depPath
+			variantName = t.Sel.Name
+		default:
+			return BasicStructField{}, errors.New("Could not parse " + variantName + " in gobridge:union on " + typeName)
 		}
+
+		err = p.queueStruct(packagePath, variantName)
+		if err != nil {
+			return BasicStructField{}, err
+		}
+
+		if !hasValue {
+			value = variantName
+		}
+
+		variants = append(variants, UnionVariant{Value: value, Type: packagePath + "-" + variantName})
 	}
 
-	if depImport == "" {
-		return BasicStructField{}, errors.New("Could not find imported package")
+	return UnionStructField{FieldName: fieldName, Discriminator: words[0], Variants: variants}, nil
+}
+
+func (p *Parser) parseDependencyField(orderedStruct OrderedStructType, fieldName string, expr *ast.SelectorExpr) (StructField, error) {
+	// nested.something
+	// time.Time
+
+	depPath, isLocal, err := p.resolveSelectorPackage(orderedStruct, expr)
+	if err != nil {
+		return BasicStructField{}, err
 	}
 
-	p.consumeDir(fullPath)
+	if !isLocal {
+		return UnknownStructField{FullType: expr.X.(*ast.Ident).Name + "." + expr.Sel.Name, FieldName: fieldName}, nil
+	}
 
-	packageStructs, exists := p.moduleStructs[fullPath]
-	if !exists {
-		return BasicStructField{}, errors.New("Should always exist at this point")
+	marshalerField, isMarshaler := p.getMarshalerField(depPath, expr.Sel.Name, fieldName)
+	if isMarshaler {
+		return marshalerField, nil
 	}
 
-	//
-	// Because we did `consumeDir`, as we don't know the exact file.
-	// We should clean up, because we don't want all other structs that we
-	// might not need present in our processing map, as they will make it
-	// into the final output.
-	//
-	// This however, raises conserns over efficiency. We are deleting a dir
-	// and perhaps re-reading it in the future. We can optimise this a tone.
-	//
+	namedField, isNamed, err := p.parseNamedType(depPath, expr.Sel.Name, fieldName)
+	if isNamed {
+		return namedField, err
+	}
 
-	cleanPackageStructs := make(NameToStructPos)
-	cleanPackageStructs[structName] = packageStructs[structName]
-	p.moduleStructs[fullPath] = cleanPackageStructs
+	err = p.queueStruct(depPath, expr.Sel.Name)
+	if err != nil {
+		return BasicStructField{}, err
+	}
 
-	return BasicStructField{name: fieldName, Type: structName}, nil
+	return BasicStructField{FieldName: fieldName, Type: depPath + "-" + expr.Sel.Name}, nil
 }
 
-func (p *Parser) parseMapField(orderedStruct OrderedStructType, fieldName string, mapAst *ast.MapType) (StructField, error) {
-	keyIdent, ok := mapAst.Key.(*ast.Ident)
-	if !ok {
-		return BasicStructField{}, errors.New(fmt.Sprintf("Only support %T as key of map type.", mapAst.Key))
+// encoding/json only allows map keys of string and integer kinds,
+// or types implementing encoding.TextMarshaler, which are encoded as strings.
+//
+// Returns the golang basic type the key will be encoded from.
+func (p *Parser) resolveMapKey(orderedStruct OrderedStructType, key ast.Expr) (string, error) {
+	switch t := key.(type) {
+	case *ast.Ident:
+		basicType := getBasicTypeName(t.Name)
+
+		jsType, err := getJsType(basicType)
+		if err == nil {
+			if jsType == "string" || isIntegerType(basicType) {
+				return basicType, nil
+			}
+
+			return "", unsupportedError("encoding/json does not support %s as key of map type.", t.Name)
+		}
+
+		return p.resolveNamedMapKey(orderedStruct.PackagePath, t.Name)
+	case *ast.SelectorExpr:
+		depPath, isLocal, err := p.resolveSelectorPackage(orderedStruct, t)
+		if err != nil {
+			return "", err
+		}
+
+		// External types, such as uuid.UUID, are keys through encoding.TextMarshaler
+		if !isLocal {
+			return "string", nil
+		}
+
+		return p.resolveNamedMapKey(depPath, t.Sel.Name)
+	case *ast.StarExpr:
+		return p.resolvePointerMapKey(orderedStruct, t)
+	default:
+		return "", unsupportedError("Only support %T as key of map type.", key)
 	}
+}
 
-	valueIdent, ok := mapAst.Value.(*ast.Ident)
-	if !ok {
-		valueType, err := p.parseStructFieldType(orderedStruct, fieldName, mapAst.Value)
+// encoding/json never follows a pointer key,
+// so it is only a key when it implements encoding.TextMarshaler.
+func (p *Parser) resolvePointerMapKey(orderedStruct OrderedStructType, key *ast.StarExpr) (string, error) {
+	switch t := key.X.(type) {
+	case *ast.Ident:
+		if p.marshalers[orderedStruct.PackagePath+"-"+t.Name] == MARSHAL_TEXT {
+			return "string", nil
+		}
+	case *ast.SelectorExpr:
+		depPath, isLocal, err := p.resolveSelectorPackage(orderedStruct, t)
 		if err != nil {
-			return BasicStructField{}, err
+			return "", err
+		}
+
+		if !isLocal || p.marshalers[depPath+"-"+t.Sel.Name] == MARSHAL_TEXT {
+			return "string", nil
+		}
+	}
+
+	return "", unsupportedError("encoding/json does not support %s as key of map type, it needs to implement encoding.TextMarshaler.", types.ExprString(key))
+}
+
+func (p *Parser) resolveNamedMapKey(packagePath string, typeName string) (string, error) {
+	namespacedName := packagePath + "-" + typeName
+
+	namedType, exists := p.namedTypes[namespacedName]
+	if exists {
+		keyType, err := p.resolveMapKey(OrderedStructType{File: namedType.File, PackagePath: namedType.PackagePath}, namedType.Type)
+
+		// String kinds are used directly, even if they implement encoding.TextMarshaler.
+		if err == nil && keyType == "string" {
+			return keyType, nil
 		}
 
-		return MapStructField{
-			name:    fieldName,
-			KeyType: keyIdent.Name,
-			Value:   valueType,
-		}, nil
+		if p.marshalers[namespacedName] == MARSHAL_TEXT {
+			return "string", nil
+		}
+
+		return keyType, err
 	}
 
-	_, err := getJsType(valueIdent.Name)
+	if p.getMarshaler(packagePath, typeName) == MARSHAL_TEXT {
+		return "string", nil
+	}
+
+	return "", unsupportedError("encoding/json does not support %s as key of map type, it needs to implement encoding.TextMarshaler.", typeName)
+}
+
+func (p *Parser) parseMapField(orderedStruct OrderedStructType, fieldName string, mapAst *ast.MapType) (StructField, error) {
+	keyType, err := p.resolveMapKey(orderedStruct, mapAst.Key)
+	if err != nil {
+		return BasicStructField{}, err
+	}
+
+	valueType, err := p.parseStructFieldType(orderedStruct, fieldName, mapAst.Value)
 	if err != nil {
-		return MapStructField{name: fieldName, KeyType: keyIdent.Name, Value: BasicStructField{name: fieldName, Type: orderedStruct.PackagePath + "-" + valueIdent.Name}}, nil
+		return BasicStructField{}, err
 	}
 
-	return MapStructField{name: fieldName, KeyType: keyIdent.Name, Value: BasicStructField{name: fieldName, Type: valueIdent.Name}}, nil
+	return MapStructField{
+		FieldName: fieldName,
+		KeyType:   keyType,
+		Value:     valueType,
+	}, nil
+}
+
+// byte and rune are aliases, and uintptr is encoded like uint,
+// so only one name is used for each of them.
+func getBasicTypeName(name string) string {
+	switch name {
+	case "byte":
+		return "uint8"
+	case "rune":
+		return "int32"
+	case "uintptr":
+		return "uint"
+	}
+
+	return name
 }
 
 func (p *Parser) parseStructFieldType(orderedStruct OrderedStructType, fieldName string, field ast.Expr) (StructField, error) {
 	switch t := field.(type) {
 	case *ast.Ident:
+		basicType := getBasicTypeName(t.Name)
+
+		_, err := getJsType(basicType)
+		if err == nil {
+			return BasicStructField{FieldName: fieldName, Type: basicType}, nil
+		}
+
+		if t.Name == "any" {
+			return UnknownStructField{FieldName: fieldName, FullType: ANY_TYPE}, nil
+		}
+
+		marshalerField, isMarshaler := p.getMarshalerField(orderedStruct.PackagePath, t.Name, fieldName)
+		if isMarshaler {
+			return marshalerField, nil
+		}
+
+		namedField, isNamed, err := p.parseNamedType(orderedStruct.PackagePath, t.Name, fieldName)
+		if isNamed {
+			return namedField, err
+		}
+
 		// Same package dependant structs go in here.
-		_, err := getJsType(t.Name)
+		err = p.queueStruct(orderedStruct.PackagePath, t.Name)
 		if err != nil {
-			return BasicStructField{name: fieldName, Type: orderedStruct.PackagePath + "-" + t.Name}, nil
+			return BasicStructField{}, err
 		}
 
-		return BasicStructField{name: fieldName, Type: t.Name}, nil
+		return BasicStructField{FieldName: fieldName, Type: orderedStruct.PackagePath + "-" + t.Name}, nil
 	case *ast.SelectorExpr:
 		return p.parseDependencyField(orderedStruct, fieldName, t)
 	case *ast.StarExpr:
@@ -299,9 +922,22 @@ func (p *Parser) parseStructFieldType(orderedStruct OrderedStructType, fieldName
 			return field, err
 		}
 
-		return ArrayStructField{name: field.Name(), Type: field}, err
+		// Slices have no length
+		if t.Len == nil {
+			return ArrayStructField{FieldName: field.Name(), Type: field}, nil
+		}
+
+		length, err := p.parseArrayLength(orderedStruct, t.Len)
+		if err != nil {
+			return BasicStructField{}, err
+		}
+
+		return ArrayStructField{FieldName: field.Name(), Type: field, Fixed: true, Length: length}, nil
 	case *ast.MapType:
 		return p.parseMapField(orderedStruct, fieldName, t)
+	case *ast.InterfaceType:
+		// Interfaces without a gobridge:union directive could be anything.
+		return UnknownStructField{FieldName: fieldName, FullType: ANY_TYPE}, nil
 	case *ast.StructType:
 		orderedStruct.StructType = t
 		fields, err := p.parseStruct(orderedStruct)
@@ -310,36 +946,133 @@ func (p *Parser) parseStructFieldType(orderedStruct OrderedStructType, fieldName
 			return BasicStructField{}, err
 		}
 
-		return AnonStructField{name: fieldName, Fields: fields}, nil
+		return AnonStructField{FieldName: fieldName, Fields: fields}, nil
 	default:
-		return BasicStructField{}, errors.New(fmt.Sprintf("Currently, we don't support %T types.", field))
+		return BasicStructField{}, unsupportedError("Currently, we don't support %T types.", field)
+	}
+}
+
+func getFieldTag(field *ast.Field) (string, error) {
+	if field.Tag == nil {
+		return "", nil
+	}
+
+	return strconv.Unquote(field.Tag.Value)
+}
+
+func (p *Parser) isStruct(packagePath string, typeName string) bool {
+	pkg, exists := p.packages[packagePath]
+	if !exists {
+		return false
+	}
+
+	_, exists = pkg.Structs[packagePath+"-"+typeName]
+	return exists
+}
+
+func (p *Parser) parseEmbeddedStructField(orderedStruct OrderedStructType, packagePath string, structName string) (StructField, error) {
+	// encoding/json treats embedded non struct types as a field named after the type.
+	namedField, isNamed, err := p.parseNamedType(packagePath, structName, structName)
+	if isNamed && !ast.IsExported(structName) {
+		return nil, nil
+	}
+
+	if isNamed {
+		return namedField, err
+	}
+
+	err = p.queueStruct(packagePath, structName)
+	if err != nil {
+		return BasicStructField{}, errors.New(fmt.Sprintf("Could not find embedded struct %s in %s", structName, orderedStruct.StructName))
+	}
+
+	//
+	// Embedded structs are resolved during post processing,
+	// once every struct has been parsed.
+	//
+	return EmbeddedStructField{FieldName: structName, Type: packagePath + "-" + structName}, nil
+}
+
+// The name of an embedded type, which is also the name of its field.
+func getEmbeddedTypeName(fieldType ast.Expr) string {
+	switch t := fieldType.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	default:
+		return types.ExprString(fieldType)
+	}
+}
+
+func (p *Parser) parseEmbeddedField(orderedStruct OrderedStructType, fieldType ast.Expr) (StructField, error) {
+	switch t := fieldType.(type) {
+	case *ast.StarExpr:
+		// encoding/json ignores embedded pointers to unexported types.
+		if !ast.IsExported(getEmbeddedTypeName(t.X)) {
+			return nil, nil
+		}
+
+		// Embedded pointers are promoted the same way as embedded structs.
+		return p.parseEmbeddedField(orderedStruct, t.X)
+	case *ast.Ident:
+		// Embedded predeclared types, such as `int`, are unexported.
+		_, err := getJsType(t.Name)
+		if err == nil {
+			return nil, nil
+		}
+
+		// So are unexported types that aren't structs, such as `error`.
+		if !ast.IsExported(t.Name) && !p.isStruct(orderedStruct.PackagePath, t.Name) {
+			return nil, nil
+		}
+
+		return p.parseEmbeddedStructField(orderedStruct, orderedStruct.PackagePath, t.Name)
+	case *ast.SelectorExpr:
+		depPath, isLocal, err := p.resolveSelectorPackage(orderedStruct, t)
+		if err != nil {
+			return BasicStructField{}, err
+		}
+
+		if !isLocal {
+			return p.parseDependencyField(orderedStruct, t.Sel.Name, t)
+		}
+
+		return p.parseEmbeddedStructField(orderedStruct, depPath, t.Sel.Name)
+	default:
+		return BasicStructField{}, unsupportedError("Do not currently support %T types on embedded", fieldType)
 	}
 }
 
 func (p *Parser) parseStructField(orderedStruct OrderedStructType, field *ast.Field) ([]StructField, error) {
 	if len(field.Names) > 1 {
-		return []StructField{}, errors.New("More than one name returned")
+		return []StructField{}, unsupportedError("More than one name returned")
+	}
+
+	tag, err := getFieldTag(field)
+	if err != nil {
+		return []StructField{}, err
 	}
 
 	isEmbeddedField := len(field.Names) == 0
 
 	if isEmbeddedField {
-		switch field.Type.(type) {
-		case *ast.Ident:
-			ident := field.Type.(*ast.Ident)
-			return p.parseEmbeddedStructField(orderedStruct, ident.Name)
-		case *ast.SelectorExpr:
-			selector := field.Type.(*ast.SelectorExpr)
-			embeddedDepField, err := p.parseDependencyField(orderedStruct, EMBEDDED_DEP, selector)
-
-			if err != nil {
-				return []StructField{}, err
-			}
+		embeddedField, err := p.parseEmbeddedField(orderedStruct, field.Type)
+		if err != nil {
+			return []StructField{}, err
+		}
 
-			return []StructField{embeddedDepField}, nil
-		default:
-			return []StructField{}, errors.New(fmt.Sprintf("Do not currently support %T types on embedded", field.Type))
+		// Unexported embedded types that aren't structs are ignored.
+		if embeddedField == nil {
+			return []StructField{}, nil
 		}
+
+		return []StructField{withTag(embeddedField, tag)}, nil
+	}
+
+	// encoding/json ignores unexported fields, even with a tag.
+	if !ast.IsExported(field.Names[0].Name) {
+		return []StructField{}, nil
 	}
 
 	structFields, err := p.parseStructFieldType(orderedStruct, field.Names[0].Name, field.Type)
@@ -347,7 +1080,65 @@ func (p *Parser) parseStructField(orderedStruct OrderedStructType, field *ast.Fi
 		return []StructField{}, err
 	}
 
-	return []StructField{structFields}, nil
+	return []StructField{withTag(structFields, tag)}, nil
+}
+
+// Points the error at the field it came from,
+// unless it already points at a field of an anonymous struct.
+func (p *Parser) getFieldError(orderedStruct OrderedStructType, field *ast.Field, err error) error {
+	var sourceError SourceError
+	if errors.As(err, &sourceError) {
+		return err
+	}
+
+	fieldName := types.ExprString(field.Type)
+	if len(field.Names) > 0 {
+		fieldName = field.Names[0].Name
+	}
+
+	position := p.fileSet.Position(field.Pos())
+
+	return SourceError{
+		File:   position.Filename,
+		Line:   position.Line,
+		Column: position.Column,
+
+		Struct: orderedStruct.StructName,
+		Field:  fieldName,
+
+		Err: err,
+	}
+}
+
+func (p *Parser) getPosition(pos token.Pos) Position {
+	position := p.fileSet.Position(pos)
+
+	return Position{
+		File:   position.Filename,
+		Line:   position.Line,
+		Column: position.Column,
+	}
+}
+
+// Embedded fields have no name to put a placeholder under, so they are left out.
+func getPlaceholderFields(field *ast.Field) []StructField {
+	tag, _ := getFieldTag(field)
+
+	placeholders := make([]StructField, 0, len(field.Names))
+	for _, name := range field.Names {
+		placeholders = append(placeholders, UnknownStructField{FieldName: name.Name, StructTag: tag, FullType: ANY_TYPE})
+	}
+
+	return placeholders
+}
+
+// The comment above a field, or after it on the same line.
+func getFieldDoc(field *ast.Field) string {
+	if field.Doc != nil {
+		return field.Doc.Text()
+	}
+
+	return field.Comment.Text()
 }
 
 func (p *Parser) parseStruct(orderedStruct OrderedStructType) ([]StructField, error) {
@@ -356,10 +1147,22 @@ func (p *Parser) parseStruct(orderedStruct OrderedStructType) ([]StructField, er
 	for _, field := range orderedStruct.Fields.List {
 		processedFields, err := p.parseStructField(orderedStruct, field)
 		if err != nil {
-			return []StructField{}, err
+			err = p.getFieldError(orderedStruct, field, err)
+			if p.report == nil {
+				return []StructField{}, err
+			}
+
+			//
+			// Carry on with a placeholder, so one bad field
+			// doesn't hide the problems in the rest of the package.
+			//
+			p.report(err)
+			processedFields = getPlaceholderFields(field)
 		}
 
-		structFields = append(structFields, processedFields...)
+		for _, processedField := range processedFields {
+			structFields = append(structFields, withSource(processedField, getFieldDoc(field), p.getPosition(field.Pos())))
+		}
 	}
 
 	return structFields, nil
@@ -368,65 +1171,81 @@ func (p *Parser) parseStruct(orderedStruct OrderedStructType) ([]StructField, er
 func (p *Parser) Parse() ([]Struct, error) {
 	processedStructs := make([]Struct, 0)
 
-	for len(p.moduleStructs) > 0 {
-
-		for packageName, packageStructs := range p.moduleStructs {
-			for structName, s := range packageStructs {
-				fields, err := p.parseStruct(s)
-				if err != nil {
-					return []Struct{}, err
-				}
-
-				parsedStruct := Struct{
-					Name:   structName,
-					Order:  s.Order,
-					Fields: fields,
-				}
-
-				processedStructs = append(processedStructs, parsedStruct)
-			}
+	//
+	// Parsing a struct can queue more structs, from the same package
+	// or from dependencies. Once the queue is empty, every struct we
+	// could possibly need has been parsed.
+	//
+	for len(p.queue) > 0 {
+		s := p.queue[0]
+		p.queue = p.queue[1:]
 
-			delete(p.moduleStructs, packageName)
+		fields, err := p.parseStruct(s)
+		if err != nil {
+			return []Struct{}, err
 		}
 
+		processedStructs = append(processedStructs, Struct{
+			Name:        s.PackagePath + "-" + s.StructName,
+			Order:       s.Order,
+			PackagePath: s.PackagePath,
+			Doc:         s.Doc,
+			Position:    p.getPosition(s.Pos),
+			Fields:      fields,
+		})
 	}
 
 	//
-	// When we are processing embedded struct fields two things can happen.
-	// 1. It's embedded from a struct in the same package. At which point we can
-	//    grab it's fields very easily.
-	//
-	// 2. They are a dependency struct, which then are added as a BasicStructField,
-	//    and the dependency is resolved later on.
-	//
-	// For approach 2, we need to do some post procesing, to get the struct fields
-	// in the correct position.
+	// Embedded structs are left as EmbeddedStructField while parsing,
+	// as the struct they embed might not have been parsed yet.
 	//
-	// This creates two approaches for the same thing. Which is not ideal.
-	// I actually think we could move the embedded structs over to the backend,
-	// but I'm not sure yet. Until then, we keep both approaches.
+	// Now that every struct is available, a single pass replaces them
+	// with their promoted fields, following the same rules as encoding/json.
 	//
 
-	for i, s := range processedStructs {
-		processedStructs[i] = rebuildStruct(processedStructs, s)
+	return promoteStructs(processedStructs)
+}
+
+// Queues the given structs, from whichever package declares them,
+// or every struct of the packages without any.
+func (p *Parser) queueRootTypes(pkgs []*Package, typeNames []string) error {
+	if len(typeNames) == 0 {
+		for _, pkg := range pkgs {
+			p.queuePackage(pkg)
+		}
+
+		return nil
 	}
 
-	return processedStructs, nil
-}
+	for _, typeName := range typeNames {
+		index := slices.IndexFunc(pkgs, func(pkg *Package) bool {
+			_, exists := pkg.Structs[pkg.Path+"-"+typeName]
+			return exists
+		})
 
-func ParserFactory(entryFile string, givenProjectPath string) (Parser, error) {
-	p := Parser{
-		projectPath:   givenProjectPath,
-		moduleStructs: make(ModuleStructs),
+		if index == -1 {
+			return errors.New("Could not find struct " + typeName + " in the entry packages")
+		}
+
+		err := p.queueStruct(pkgs[index].Path, typeName)
+		if err != nil {
+			return err
+		}
 	}
 
-	path := filepath.Dir(entryFile)
+	return nil
+}
 
-	mainPackage, err := p.consumeDir(path)
+func ParserFactory(entryFile string, givenProjectPath string) (Parser, error) {
+	p := newParser(givenProjectPath)
+
+	pkg, err := p.loadPackage(filepath.Dir(entryFile))
 	if err != nil {
 		return Parser{}, err
 	}
 
-	p.entryPackage = mainPackage
+	p.entryPackage = pkg.Name
+	p.queuePackage(pkg)
+
 	return p, nil
 }
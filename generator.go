@@ -0,0 +1,141 @@
+package gobridge
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+const VALIBOT_TARGET = "valibot"
+
+/*
+ * Turns the structs into code, one piece at a time.
+ * A new generator is made for every output, so it can keep track
+ * of what it used along the way, such as the imports it needs.
+ */
+type Generator interface {
+	// Called before any struct, with the structs in order.
+	// The output is written after every struct, so it can import what they used.
+	Header(structList StructList) string
+
+	/* Declares a struct, from its fields that have already been rendered */
+	Struct(s Struct, fields []string) string
+
+	/* A field of a struct, at the given depth of anonymous structs */
+	Field(field StructField, typeExpression string, indent uint) string
+
+	/* The type of a field, without its name */
+	TypeExpression(field StructField, indent uint) (string, error)
+
+	/* Written after every struct */
+	Footer(structList StructList) string
+}
+
+// Generators for languages without `//` comments implement it,
+// so Options.Header is written in comments they understand.
+// Formats without comments return "", and are left without the header.
+type Commenter interface {
+	Comment(line string) string
+}
+
+func getHeaderComment(g Generator, header string) string {
+	if header == "" {
+		return ""
+	}
+
+	comment := ""
+	for _, line := range strings.Split(strings.TrimSuffix(header, "\n"), "\n") {
+		commenter, ok := g.(Commenter)
+		if ok && commenter.Comment(line) == "" {
+			continue
+		} else if ok {
+			comment += commenter.Comment(line) + "\n"
+		} else {
+			comment += "// " + line + "\n"
+		}
+	}
+
+	return comment
+}
+
+// Makes a generator for one output, structList is in the order of OrderStructs.
+type NewGenerator func(structList StructList, options Options) Generator
+
+// Map: target -> how to make its generator
+var generators = map[string]NewGenerator{
+	VALIBOT_TARGET:      newValibotGenerator,
+	PYDANTIC_TARGET:     newPydanticGenerator,
+	OPENAPI_TARGET:      newOpenAPIGenerator,
+	OPENAPI_JSON_TARGET: newOpenAPIJsonGenerator,
+	PROTOBUF_TARGET:     newProtobufGenerator,
+	ZOD_TARGET:          newZodGenerator,
+	JSON_SCHEMA_TARGET:  newJsonSchemaGenerator,
+}
+
+// Makes a target available to Generate, and to -target.
+// Registering a target again replaces it.
+func RegisterGenerator(target string, newGenerator NewGenerator) {
+	generators[target] = newGenerator
+}
+
+// Every target that can be generated, sorted.
+func Targets() []string {
+	targets := make([]string, 0, len(generators))
+	for target := range generators {
+		targets = append(targets, target)
+	}
+
+	sort.Strings(targets)
+	return targets
+}
+
+// Generates the code of a target for every struct,
+// which must be in the order of OrderStructs.
+func Generate(target string, structList StructList, options Options) (string, error) {
+	newGenerator, exists := generators[target]
+	if !exists {
+		return "", errors.New("Unknown target " + target + ", the targets are " + strings.Join(Targets(), ", "))
+	}
+
+	// Every field of the same type would warn us again.
+	warned := make(map[string]bool)
+	warn := options.Warn
+	options.Warn = func(message string) {
+		if warn == nil || warned[message] {
+			return
+		}
+
+		warned[message] = true
+		warn(message)
+	}
+
+	g := newGenerator(structList, options)
+
+	output, err := runGenerator(g, structList)
+	if err != nil {
+		return "", err
+	}
+
+	return getHeaderComment(g, options.Header) + output, nil
+}
+
+func runGenerator(g Generator, structList StructList) (string, error) {
+	output := ""
+
+	for _, s := range structList {
+		fields := make([]string, 0, len(s.Fields))
+
+		for _, field := range s.Fields {
+			typeExpression, err := g.TypeExpression(field, 0)
+			if err != nil {
+				return "", err
+			}
+
+			fields = append(fields, g.Field(field, typeExpression, 0))
+		}
+
+		output += g.Struct(s, fields)
+	}
+
+	return g.Header(structList) + output + g.Footer(structList), nil
+}
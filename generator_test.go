@@ -0,0 +1,83 @@
+package gobridge
+
+import (
+	"strings"
+	"testing"
+)
+
+// Writes every struct as a line of its field names and types.
+type namesGenerator struct {
+	count int
+}
+
+func (g *namesGenerator) Header(structList StructList) string {
+	return "# " + strings.Repeat("*", g.count) + "\n"
+}
+
+func (g *namesGenerator) Struct(s Struct, fields []string) string {
+	g.count++
+	return getName(s.Name) + ":" + strings.Join(fields, "") + "\n"
+}
+
+func (g *namesGenerator) Field(field StructField, typeExpression string, indent uint) string {
+	return " " + field.Name() + "=" + typeExpression
+}
+
+func (g *namesGenerator) TypeExpression(field StructField, indent uint) (string, error) {
+	switch t := field.(type) {
+	case BasicStructField:
+		return getDisplayName(t.Type), nil
+	case ArrayStructField:
+		typeExpression, err := g.TypeExpression(t.Type, indent)
+		return "[]" + typeExpression, err
+	default:
+		return "?", nil
+	}
+}
+
+func (g *namesGenerator) Footer(structList StructList) string {
+	return "# end\n"
+}
+
+func TestCustomGenerator(t *testing.T) {
+	RegisterGenerator("names", func(structList StructList, options Options) Generator {
+		return &namesGenerator{}
+	})
+	defer delete(generators, "names")
+
+	structs := getCodeStructs(t, `
+package types
+
+type A struct {
+  B  []B
+  ID string
+}
+
+type B struct {
+  X   int
+  Any interface{}
+}
+`)
+
+	output, err := Generate("names", structs, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# **
+B: X=int Any=?
+A: B=[]types.B ID=string
+# end
+`
+
+	if output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+
+	_, err = Generate("typebox", structs, Options{})
+	if err == nil || err.Error() != "Unknown target typebox, the targets are jsonschema, names, openapi, openapi-json, proto, pydantic, valibot, zod" {
+		t.Log(err)
+		t.FailNow()
+	}
+}
@@ -1,3 +1,8 @@
 module github.com/JohnCosta27/go-bridge
 
 go 1.22.3
+
+require (
+	golang.org/x/mod v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
+)
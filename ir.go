@@ -0,0 +1,330 @@
+package gobridge
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Bumped whenever a change to the IR would break the tools reading it.
+const IR_VERSION = 1
+
+const (
+	BASIC_FIELD   = "basic"
+	UNKNOWN_FIELD = "unknown"
+	ARRAY_FIELD   = "array"
+	MAP_FIELD     = "map"
+	ANON_FIELD    = "anon"
+	UNION_FIELD   = "union"
+)
+
+/*
+ * The structs as JSON, for tools that aren't written in go.
+ * Structs are in the order of OrderStructs, so every struct
+ * comes after the structs it uses.
+ */
+type irDocument struct {
+	Version int `json:"version"`
+
+	/* Directories of the packages the structs came from */
+	Packages []string `json:"packages"`
+
+	Structs []irStruct `json:"structs"`
+}
+
+type irPosition struct {
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+type irStruct struct {
+	Name        string     `json:"name"`
+	PackagePath string     `json:"packagePath"`
+	Order       uint       `json:"order"`
+	Doc         string     `json:"doc,omitempty"`
+	Position    irPosition `json:"position"`
+	Fields      []irField  `json:"fields"`
+}
+
+// Every kind of field in one object, Kind says which of the others are set.
+type irField struct {
+	Kind string `json:"kind"`
+
+	Name     string      `json:"name,omitempty"`
+	Tag      string      `json:"tag,omitempty"`
+	Doc      string      `json:"doc,omitempty"`
+	Position *irPosition `json:"position,omitempty"`
+
+	/* BASIC_FIELD: a go type, or the namespaced name of a struct */
+	Type string `json:"type,omitempty"`
+
+	/* UNKNOWN_FIELD */
+	FullType string `json:"fullType,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+
+	/* ARRAY_FIELD */
+	Element *irField `json:"element,omitempty"`
+	Fixed   bool     `json:"fixed,omitempty"`
+	Length  int      `json:"length,omitempty"`
+
+	/* MAP_FIELD */
+	KeyType string   `json:"keyType,omitempty"`
+	Value   *irField `json:"value,omitempty"`
+
+	/* ANON_FIELD */
+	Fields []irField `json:"fields,omitempty"`
+
+	/* UNION_FIELD */
+	Discriminator string      `json:"discriminator,omitempty"`
+	Variants      []irVariant `json:"variants,omitempty"`
+}
+
+type irVariant struct {
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+func toIRPosition(position Position) irPosition {
+	return irPosition{File: position.File, Line: position.Line, Column: position.Column}
+}
+
+func fromIRPosition(position irPosition) Position {
+	return Position{File: position.File, Line: position.Line, Column: position.Column}
+}
+
+// Only top level fields have a position.
+func toIRFieldPosition(position Position) *irPosition {
+	if position == (Position{}) {
+		return nil
+	}
+
+	fieldPosition := toIRPosition(position)
+	return &fieldPosition
+}
+
+func toIRField(field StructField) (irField, error) {
+	switch t := field.(type) {
+	case BasicStructField:
+		return irField{Kind: BASIC_FIELD, Name: t.FieldName, Tag: t.StructTag, Doc: t.Doc, Position: toIRFieldPosition(t.Position), Type: t.Type}, nil
+	case UnknownStructField:
+		return irField{Kind: UNKNOWN_FIELD, Name: t.FieldName, Tag: t.StructTag, Doc: t.Doc, Position: toIRFieldPosition(t.Position), FullType: t.FullType, Encoding: t.Encoding}, nil
+	case ArrayStructField:
+		element, err := toIRField(t.Type)
+		if err != nil {
+			return irField{}, err
+		}
+
+		return irField{Kind: ARRAY_FIELD, Name: t.FieldName, Tag: t.StructTag, Doc: t.Doc, Position: toIRFieldPosition(t.Position), Element: &element, Fixed: t.Fixed, Length: t.Length}, nil
+	case MapStructField:
+		value, err := toIRField(t.Value)
+		if err != nil {
+			return irField{}, err
+		}
+
+		return irField{Kind: MAP_FIELD, Name: t.FieldName, Tag: t.StructTag, Doc: t.Doc, Position: toIRFieldPosition(t.Position), KeyType: t.KeyType, Value: &value}, nil
+	case AnonStructField:
+		fields, err := toIRFields(t.Fields)
+		if err != nil {
+			return irField{}, err
+		}
+
+		return irField{Kind: ANON_FIELD, Name: t.FieldName, Tag: t.StructTag, Doc: t.Doc, Position: toIRFieldPosition(t.Position), Fields: fields}, nil
+	case UnionStructField:
+		variants := make([]irVariant, 0, len(t.Variants))
+		for _, variant := range t.Variants {
+			variants = append(variants, irVariant{Value: variant.Value, Type: variant.Type})
+		}
+
+		return irField{Kind: UNION_FIELD, Name: t.FieldName, Tag: t.StructTag, Doc: t.Doc, Position: toIRFieldPosition(t.Position), Discriminator: t.Discriminator, Variants: variants}, nil
+	default:
+		return irField{}, errors.New("Cannot write " + field.Name() + " to the IR, embedded structs should have been promoted")
+	}
+}
+
+func toIRFields(fields []StructField) ([]irField, error) {
+	irFields := make([]irField, 0, len(fields))
+	for _, field := range fields {
+		converted, err := toIRField(field)
+		if err != nil {
+			return nil, err
+		}
+
+		irFields = append(irFields, converted)
+	}
+
+	return irFields, nil
+}
+
+func fromIRField(field irField) (StructField, error) {
+	position := Position{}
+	if field.Position != nil {
+		position = fromIRPosition(*field.Position)
+	}
+
+	switch field.Kind {
+	case BASIC_FIELD:
+		return BasicStructField{FieldName: field.Name, StructTag: field.Tag, Doc: field.Doc, Position: position, Type: field.Type}, nil
+	case UNKNOWN_FIELD:
+		if field.Encoding != "" && field.Encoding != MARSHAL_JSON && field.Encoding != MARSHAL_TEXT {
+			return nil, errors.New("Unknown field " + field.Name + " has an encoding of " + strconv.Quote(field.Encoding))
+		}
+
+		return UnknownStructField{FieldName: field.Name, StructTag: field.Tag, Doc: field.Doc, Position: position, FullType: field.FullType, Encoding: field.Encoding}, nil
+	case ARRAY_FIELD:
+		if field.Element == nil {
+			return nil, errors.New("Array field " + field.Name + " has no element")
+		}
+
+		element, err := fromIRField(*field.Element)
+		if err != nil {
+			return nil, err
+		}
+
+		if field.Length < 0 || (!field.Fixed && field.Length != 0) {
+			return nil, errors.New("Array field " + field.Name + " has a length of " + strconv.Itoa(field.Length))
+		}
+
+		return ArrayStructField{FieldName: field.Name, StructTag: field.Tag, Doc: field.Doc, Position: position, Type: element, Fixed: field.Fixed, Length: field.Length}, nil
+	case MAP_FIELD:
+		if field.Value == nil {
+			return nil, errors.New("Map field " + field.Name + " has no value")
+		}
+
+		if !isMapKeyType(field.KeyType) {
+			return nil, errors.New("Map field " + field.Name + " has a key of " + strconv.Quote(field.KeyType))
+		}
+
+		value, err := fromIRField(*field.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		return MapStructField{FieldName: field.Name, StructTag: field.Tag, Doc: field.Doc, Position: position, KeyType: field.KeyType, Value: value}, nil
+	case ANON_FIELD:
+		fields, err := fromIRFields(field.Fields)
+		if err != nil {
+			return nil, err
+		}
+
+		return AnonStructField{FieldName: field.Name, StructTag: field.Tag, Doc: field.Doc, Position: position, Fields: fields}, nil
+	case UNION_FIELD:
+		if field.Discriminator == "" {
+			return nil, errors.New("Union field " + field.Name + " has no discriminator")
+		}
+
+		variants := make([]UnionVariant, 0, len(field.Variants))
+		for _, variant := range field.Variants {
+			variants = append(variants, UnionVariant{Value: variant.Value, Type: variant.Type})
+		}
+
+		return UnionStructField{FieldName: field.Name, StructTag: field.Tag, Doc: field.Doc, Position: position, Discriminator: field.Discriminator, Variants: variants}, nil
+	default:
+		return nil, errors.New("Unknown kind of field " + strconv.Quote(field.Kind) + " for " + field.Name)
+	}
+}
+
+// encoding/json writes every key as a string, from a string or an integer.
+func isMapKeyType(keyType string) bool {
+	jsType, err := getJsType(keyType)
+	return err == nil && (jsType == "string" || isIntegerType(keyType))
+}
+
+func fromIRFields(irFields []irField) ([]StructField, error) {
+	fields := make([]StructField, 0, len(irFields))
+	for _, converted := range irFields {
+		field, err := fromIRField(converted)
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// Writes the structs, from Load, as a versioned JSON document.
+func MarshalIR(structList StructList, packageDirs []string) ([]byte, error) {
+	document := irDocument{
+		Version:  IR_VERSION,
+		Packages: packageDirs,
+		Structs:  make([]irStruct, 0, len(structList)),
+	}
+
+	for _, s := range structList {
+		fields, err := toIRFields(s.Fields)
+		if err != nil {
+			return nil, err
+		}
+
+		document.Structs = append(document.Structs, irStruct{
+			Name:        s.Name,
+			PackagePath: s.PackagePath,
+			Order:       s.Order,
+			Doc:         s.Doc,
+			Position:    toIRPosition(s.Position),
+			Fields:      fields,
+		})
+	}
+
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// Reads structs written by MarshalIR, so generators can run without the go code.
+// Also returns the directories of the packages they came from.
+func UnmarshalIR(data []byte) (StructList, []string, error) {
+	var document irDocument
+
+	err := json.Unmarshal(data, &document)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if document.Version != IR_VERSION {
+		return nil, nil, errors.New("IR version " + strconv.Itoa(document.Version) + " is not supported, go-bridge reads version " + strconv.Itoa(IR_VERSION))
+	}
+
+	structList := make(StructList, 0, len(document.Structs))
+	for _, s := range document.Structs {
+		// Generators take the name after the first "-".
+		if !strings.Contains(s.Name, "-") {
+			return nil, nil, errors.New("Struct " + strconv.Quote(s.Name) + " should be named packagePath-Name")
+		}
+
+		fields, err := fromIRFields(s.Fields)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		structList = append(structList, Struct{
+			Name:        s.Name,
+			PackagePath: s.PackagePath,
+			Order:       s.Order,
+			Doc:         s.Doc,
+			Position:    fromIRPosition(s.Position),
+			Fields:      fields,
+		})
+	}
+
+	//
+	// Also makes sure every struct a field uses is in the IR.
+	//
+	structList, err = OrderStructs(structList)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return structList, document.Packages, nil
+}
+
+func ReadIR(path string) (StructList, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return UnmarshalIR(data)
+}
@@ -0,0 +1,114 @@
+package gobridge
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const irCode = `
+package types
+
+// A user of the app.
+type User struct {
+  // Never changes.
+  ID      int64             ` + "`json:\"id,string\"`" + `
+  Friends [2]Friend
+  Nobody  [0]Friend
+  Scores  map[int][]float64
+  Address struct {
+    Street string
+  }
+  Pet     Pet
+  Other   interface{}
+}
+
+//gobridge:union kind Dog=dog
+type Pet interface{}
+
+type Dog struct {
+  Name string // Good boy.
+}
+
+type Friend struct {
+  Name string
+}
+`
+
+func TestIRRoundTrip(t *testing.T) {
+	structs := getCodeStructs(t, irCode)
+
+	data, err := MarshalIR(structs, []string{"types"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, packageDirs, err := UnmarshalIR(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(loaded, structs) || !reflect.DeepEqual(packageDirs, []string{"types"}) {
+		t.Log(string(data))
+		t.FailNow()
+	}
+
+	user := structs[len(structs)-1]
+	if user.Doc != "A user of the app.\n" || user.Position.Line != 5 || user.Position.Column != 6 {
+		t.Log(user.Doc, user.Position)
+		t.FailNow()
+	}
+
+	id := user.Fields[0].(BasicStructField)
+	if id.Doc != "Never changes.\n" || id.Position.Line != 7 || id.Position.Column != 3 {
+		t.Log(id.Doc, id.Position)
+		t.FailNow()
+	}
+
+	expected, err := GenerateValibot(structs, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := GenerateValibot(loaded, Options{})
+	if err != nil || output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+}
+
+func TestIRErrors(t *testing.T) {
+	_, _, err := UnmarshalIR([]byte(`{"version": 2, "structs": []}`))
+	if err == nil || !strings.Contains(err.Error(), "IR version 2") {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	_, _, err = UnmarshalIR([]byte(`{"version": 1, "structs": [{"name": "types-A", "fields": [{"kind": "pointer", "name": "B"}]}]}`))
+	if err == nil {
+		t.Log("Unknown kinds of field should fail")
+		t.FailNow()
+	}
+
+	invalid := map[string]string{
+		"Struct names without a package": `{"version": 1, "structs": [{"name": "A", "fields": []}]}`,
+		"Negative lengths":               `{"version": 1, "structs": [{"name": "types-A", "fields": [{"kind": "array", "name": "B", "fixed": true, "length": -1, "element": {"kind": "basic", "type": "int"}}]}]}`,
+		"Lengths of slices":              `{"version": 1, "structs": [{"name": "types-A", "fields": [{"kind": "array", "name": "B", "length": 2, "element": {"kind": "basic", "type": "int"}}]}]}`,
+		"Unknown encodings":              `{"version": 1, "structs": [{"name": "types-A", "fields": [{"kind": "unknown", "name": "B", "encoding": "xml"}]}]}`,
+		"Unsupported map keys":           `{"version": 1, "structs": [{"name": "types-A", "fields": [{"kind": "map", "name": "B", "keyType": "bool", "value": {"kind": "basic", "type": "int"}}]}]}`,
+		"Unions without a discriminator": `{"version": 1, "structs": [{"name": "types-A", "fields": [{"kind": "union", "name": "B", "variants": []}]}]}`,
+		"Missing structs":                `{"version": 1, "structs": [{"name": "types-A", "fields": [{"kind": "basic", "name": "B", "type": "types-B"}]}]}`,
+		"Empty types":                    `{"version": 1, "structs": [{"name": "types-A", "fields": [{"kind": "basic", "name": "B"}]}]}`,
+		"Missing variants":               `{"version": 1, "structs": [{"name": "types-A", "fields": [{"kind": "union", "name": "B", "discriminator": "kind", "variants": [{"value": "c", "type": "types-C"}]}]}]}`,
+	}
+
+	for name, document := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := UnmarshalIR([]byte(document))
+			if err == nil {
+				t.Log("Expected an error")
+				t.FailNow()
+			}
+		})
+	}
+}
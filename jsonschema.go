@@ -0,0 +1,344 @@
+package gobridge
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const JSON_SCHEMA_TARGET = "jsonschema"
+
+const (
+	JSON_SCHEMA_DIALECT = "https://json-schema.org/draft/2020-12/schema"
+	JSON_SCHEMA_REF     = "#/$defs/"
+)
+
+type orderedEntry struct {
+	Key   string
+	Value any
+}
+
+// A JSON object that keeps the order of its keys,
+// so properties are in the order of the fields and schemas in the order of the structs.
+type orderedObject []orderedEntry
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	buffer := bytes.Buffer{}
+	buffer.WriteString("{")
+
+	for i, entry := range o {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+
+		key, err := json.Marshal(entry.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(entry.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(key)
+		buffer.WriteString(":")
+		buffer.Write(value)
+	}
+
+	buffer.WriteString("}")
+	return buffer.Bytes(), nil
+}
+
+func (o orderedObject) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, entry := range o {
+		value := &yaml.Node{}
+		err := value.Encode(entry.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: entry.Key}, value)
+	}
+
+	return node, nil
+}
+
+// The regex of a number encoded as a string, without the slashes of a JS regex.
+func getNumberPattern(goType string) string {
+	return strings.Trim(getNumberRegex(goType), "/")
+}
+
+// The key of a field, unquoted, and whether encoding/json always writes it.
+func getPropertyName(field StructField) (string, bool) {
+	jsonName, _ := getJsonTagName(field.Tag())
+	if jsonName == "" {
+		jsonName = field.Name()
+	}
+
+	return jsonName, !hasJsonOption(field.Tag(), "omitempty")
+}
+
+func getObject(doc string, properties orderedObject, required []string) orderedObject {
+	schema := orderedObject{{"type", "object"}}
+
+	if doc = strings.TrimSpace(doc); doc != "" {
+		schema = append(schema, orderedEntry{"description", doc})
+	}
+
+	schema = append(schema, orderedEntry{"properties", properties})
+	if len(required) > 0 {
+		schema = append(schema, orderedEntry{"required", required})
+	}
+
+	return schema
+}
+
+/*
+ * Maps fields to JSON Schema 2020-12, which is also
+ * what the schemas of OpenAPI 3.1 are.
+ */
+type jsonSchemaMapper struct {
+	options Options
+	nameMap map[string]string
+
+	/* Where the schemas of the structs are, such as #/$defs/ */
+	refPrefix string
+}
+
+func (m *jsonSchemaMapper) getRef(namespacedName string) orderedObject {
+	return orderedObject{{"$ref", m.refPrefix + m.nameMap[namespacedName]}}
+}
+
+func (m *jsonSchemaMapper) getBasicSchema(field BasicStructField) orderedObject {
+	_, err := getJsType(field.Type)
+	if err == NoJsType {
+		return m.getRef(field.Type)
+	}
+
+	if field.Type == "string" || field.Type == "bool" {
+		if field.Type == "bool" && !hasJsonOption(field.StructTag, "string") {
+			return orderedObject{{"type", "boolean"}}
+		}
+
+		return orderedObject{{"type", "string"}}
+	}
+
+	//
+	// Numbers encoded as strings, by json:",string" or -int64 string.
+	//
+	if hasJsonOption(field.StructTag, "string") || (isInt64Type(field.Type) && m.options.Int64 == Int64AsString) {
+		return orderedObject{{"type", "string"}, {"pattern", getNumberPattern(field.Type)}}
+	}
+
+	switch field.Type {
+	case "float32":
+		return orderedObject{{"type", "number"}, {"format", "float"}}
+	case "float64":
+		return orderedObject{{"type", "number"}, {"format", "double"}}
+	}
+
+	schema := orderedObject{{"type", "integer"}}
+
+	switch field.Type {
+	case "int32":
+		schema = append(schema, orderedEntry{"format", "int32"})
+	case "int64", "uint64":
+		schema = append(schema, orderedEntry{"format", "int64"})
+	}
+
+	if !m.options.IntegerChecks {
+		return schema
+	}
+
+	min, max, hasRange := getIntegerRange(field.Type)
+	if hasRange {
+		return append(schema, orderedEntry{"minimum", min}, orderedEntry{"maximum", max})
+	}
+
+	if isUnsignedType(field.Type) {
+		return append(schema, orderedEntry{"minimum", 0})
+	}
+
+	return schema
+}
+
+// Each variant is the struct with the discriminator set to its value,
+// the discriminator keyword is for OpenAPI tools, JSON Schema ignores it.
+func (m *jsonSchemaMapper) getUnionSchema(union UnionStructField) orderedObject {
+	oneOf := make([]orderedObject, 0, len(union.Variants))
+	mapping := make(orderedObject, 0, len(union.Variants))
+
+	for _, variant := range union.Variants {
+		discriminator := orderedObject{{union.Discriminator, orderedObject{{"const", variant.Value}}}}
+
+		oneOf = append(oneOf, append(m.getRef(variant.Type), orderedEntry{"properties", discriminator}, orderedEntry{"required", []string{union.Discriminator}}))
+		mapping = append(mapping, orderedEntry{variant.Value, m.refPrefix + m.nameMap[variant.Type]})
+	}
+
+	discriminator := orderedObject{{"propertyName", union.Discriminator}, {"mapping", mapping}}
+	return orderedObject{{"oneOf", oneOf}, {"discriminator", discriminator}}
+}
+
+func (m *jsonSchemaMapper) getFieldSchema(field StructField) (orderedObject, error) {
+	switch t := field.(type) {
+	case BasicStructField:
+		return m.getBasicSchema(t), nil
+	case UnknownStructField:
+		switch t.Encoding {
+		case MARSHAL_JSON:
+			m.options.warn(t.FullType + " implements json.Marshaler, using an empty schema as we can't know its schema.")
+		case MARSHAL_TEXT:
+			return orderedObject{{"type", "string"}}, nil
+		}
+
+		return orderedObject{}, nil
+	case ArrayStructField:
+		if isByteSlice(t) {
+			return orderedObject{{"type", []string{"string", "null"}}, {"contentEncoding", "base64"}}, nil
+		}
+
+		items, err := m.getFieldSchema(t.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		//
+		// encoding/json writes nil slices as null,
+		// which is a second type instead of OpenAPI 3.0's nullable.
+		//
+		if !t.Fixed {
+			return orderedObject{{"type", []string{"array", "null"}}, {"items", items}}, nil
+		}
+
+		return orderedObject{{"type", "array"}, {"items", items}, {"minItems", t.Length}, {"maxItems", t.Length}}, nil
+	case MapStructField:
+		value, err := m.getFieldSchema(t.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		schema := orderedObject{{"type", []string{"object", "null"}}}
+		if isIntegerType(t.KeyType) {
+			schema = append(schema, orderedEntry{"propertyNames", orderedObject{{"pattern", getNumberPattern(t.KeyType)}}})
+		}
+
+		return append(schema, orderedEntry{"additionalProperties", value}), nil
+	case AnonStructField:
+		return m.getObjectSchema("", t.Fields)
+	case UnionStructField:
+		return m.getUnionSchema(t), nil
+	default:
+		return nil, unsupportedError("Cannot generate a JSON schema for " + field.Name())
+	}
+}
+
+func (m *jsonSchemaMapper) addProperty(properties *orderedObject, required *[]string, field StructField) error {
+	schema, err := m.getFieldSchema(field)
+	if err != nil {
+		return err
+	}
+
+	if doc := strings.TrimSpace(getDoc(field)); doc != "" {
+		schema = append(schema, orderedEntry{"description", doc})
+	}
+
+	name, isRequired := getPropertyName(field)
+	*properties = append(*properties, orderedEntry{name, schema})
+
+	if isRequired {
+		*required = append(*required, name)
+	}
+
+	return nil
+}
+
+func (m *jsonSchemaMapper) getObjectSchema(doc string, fields []StructField) (orderedObject, error) {
+	properties := make(orderedObject, 0, len(fields))
+	required := make([]string, 0, len(fields))
+
+	for _, field := range fields {
+		err := m.addProperty(&properties, &required, field)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return getObject(doc, properties, required), nil
+}
+
+/*
+ * A JSON Schema document, with every struct in $defs.
+ * Fields are added as they come, and become an object with their struct.
+ */
+type jsonSchemaGenerator struct {
+	jsonSchemaMapper
+
+	/* Map: name -> schema */
+	schemas orderedObject
+
+	// The properties of the struct being generated,
+	// and the ones encoding/json always writes.
+	properties orderedObject
+	required   []string
+}
+
+func newJsonSchemaGenerator(structList StructList, options Options) Generator {
+	return newJsonSchemaGeneratorWithRefs(structList, options, JSON_SCHEMA_REF)
+}
+
+func newJsonSchemaGeneratorWithRefs(structList StructList, options Options, refPrefix string) *jsonSchemaGenerator {
+	return &jsonSchemaGenerator{
+		jsonSchemaMapper: jsonSchemaMapper{
+			options:   options,
+			nameMap:   getNameMap(structList, options),
+			refPrefix: refPrefix,
+		},
+		schemas:    make(orderedObject, 0),
+		properties: make(orderedObject, 0),
+		required:   make([]string, 0),
+	}
+}
+
+func (g *jsonSchemaGenerator) Header(structList StructList) string {
+	return ""
+}
+
+func (g *jsonSchemaGenerator) TypeExpression(field StructField, indent uint) (string, error) {
+	return "", g.addProperty(&g.properties, &g.required, field)
+}
+
+func (g *jsonSchemaGenerator) Field(field StructField, typeExpression string, indent uint) string {
+	return ""
+}
+
+func (g *jsonSchemaGenerator) Struct(s Struct, fields []string) string {
+	g.schemas = append(g.schemas, orderedEntry{g.nameMap[s.Name], getObject(s.Doc, g.properties, g.required)})
+
+	g.properties = make(orderedObject, 0)
+	g.required = make([]string, 0)
+
+	return ""
+}
+
+// JSON has no comments, so it is left without the header.
+func (g *jsonSchemaGenerator) Comment(line string) string {
+	return ""
+}
+
+func (g *jsonSchemaGenerator) Footer(structList StructList) string {
+	return encodeJson(orderedObject{{"$schema", JSON_SCHEMA_DIALECT}, {"$defs", g.schemas}})
+}
+
+func encodeJson(value any) string {
+	output, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	return string(output) + "\n"
+}
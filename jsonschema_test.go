@@ -0,0 +1,127 @@
+package gobridge
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJsonSchema(t *testing.T) {
+	structs := getCodeStructs(t, `
+package types
+
+// Somebody using the app.
+type User struct {
+  ID      int64             `+"`json:\"id\"`"+`
+  Name    string            `+"`json:\"name,omitempty\"`"+`
+  Scores  map[int]float64   `+"`json:\"scores\"`"+`
+  From    [2]string         `+"`json:\"from\"`"+`
+  Avatar  []byte            `+"`json:\"avatar\"`"+`
+  Address struct {
+    Street string `+"`json:\"street\"`"+`
+  } `+"`json:\"address\"`"+`
+}
+`)
+
+	output, err := Generate(JSON_SCHEMA_TARGET, structs, Options{Int64: Int64AsString, Header: "Generated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var document any
+	err = json.Unmarshal([]byte(output), &document)
+	if err != nil {
+		t.Log(output)
+		t.Fatal(err)
+	}
+
+	var expected any
+	err = json.Unmarshal([]byte(`{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$defs": {
+    "User": {
+      "type": "object",
+      "description": "Somebody using the app.",
+      "properties": {
+        "id": { "type": "string", "pattern": "^-?\\d+$" },
+        "name": { "type": "string" },
+        "scores": {
+          "type": ["object", "null"],
+          "propertyNames": { "pattern": "^-?\\d+$" },
+          "additionalProperties": { "type": "number", "format": "double" }
+        },
+        "from": { "type": "array", "items": { "type": "string" }, "minItems": 2, "maxItems": 2 },
+        "avatar": { "type": ["string", "null"], "contentEncoding": "base64" },
+        "address": {
+          "type": "object",
+          "properties": { "street": { "type": "string" } },
+          "required": ["street"]
+        }
+      },
+      "required": ["id", "scores", "from", "avatar", "address"]
+    }
+  }
+}`), &expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(document, expected) {
+		t.Log(output)
+		t.FailNow()
+	}
+}
+
+func TestJsonSchemaUnion(t *testing.T) {
+	output, err := Generate(JSON_SCHEMA_TARGET, getCodeStructs(t, unionCode), Options{Header: "Generated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var document struct {
+		Schema string                     `json:"$schema"`
+		Defs   map[string]json.RawMessage `json:"$defs"`
+	}
+
+	err = json.Unmarshal([]byte(output), &document)
+	if err != nil {
+		t.Log(output)
+		t.Fatal(err)
+	}
+
+	if document.Schema != JSON_SCHEMA_DIALECT || len(document.Defs) != 3 {
+		t.Log(output)
+		t.FailNow()
+	}
+
+	var drawing struct {
+		Properties struct {
+			Main any `json:"main"`
+		} `json:"properties"`
+	}
+
+	err = json.Unmarshal(document.Defs["Drawing"], &drawing)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected any
+	err = json.Unmarshal([]byte(`{
+  "oneOf": [
+    { "$ref": "#/$defs/Circle", "properties": { "type": { "const": "circle" } }, "required": ["type"] },
+    { "$ref": "#/$defs/Square", "properties": { "type": { "const": "square" } }, "required": ["type"] }
+  ],
+  "discriminator": {
+    "propertyName": "type",
+    "mapping": { "circle": "#/$defs/Circle", "square": "#/$defs/Square" }
+  }
+}`), &expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(drawing.Properties.Main, expected) {
+		t.Log(output)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,328 @@
+package gobridge
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	NotAnObject           = errors.New("The OpenAPI document should be an object")
+	ComponentsNotAnObject = errors.New("components in the OpenAPI document should be an object")
+	FlowDocument          = errors.New("The OpenAPI document should be written in block style, or as JSON")
+)
+
+/*
+ * Replaces components.schemas of an OpenAPI document, YAML or JSON,
+ * with the schemas of the structs. Only the text of the schemas is
+ * replaced, so everything else is left byte for byte as it was,
+ * with its comments, quotes and indentation.
+ */
+func MergeOpenAPI(document []byte, structList StructList, options Options) ([]byte, error) {
+	g := newOpenAPIGenerator(structList, options).(*openapiGenerator)
+
+	_, err := runGenerator(g, structList)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(document), []byte("{")) {
+		return mergeJsonSchemas(document, g.schemas)
+	}
+
+	return mergeYamlSchemas(document, g.schemas)
+}
+
+// The index of a key in a YAML mapping, or -1.
+func getMappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// The spaces a YAML document indents by, from the first mapping
+// in a mapping, or 2 when it has none.
+func getYamlIndent(mapping *yaml.Node) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		value := mapping.Content[i+1]
+		if value.Kind == yaml.MappingNode && value.Style != yaml.FlowStyle && len(value.Content) > 0 {
+			return max(value.Content[0].Column-mapping.Content[i].Column, 2)
+		}
+	}
+
+	return 2
+}
+
+// The line after the value of a key in block style, which is every
+// line after the key that is more indented than it. Blank lines and
+// comments at the end belong to whatever comes next.
+func getBlockEnd(lines []string, keyLine int, keyIndent int) int {
+	end := keyLine + 1
+
+	for i := keyLine + 1; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r\n")
+		trimmed := strings.TrimLeft(line, " ")
+
+		if trimmed == "" {
+			continue
+		}
+
+		if len(line)-len(trimmed) <= keyIndent {
+			break
+		}
+
+		end = i + 1
+	}
+
+	return end
+}
+
+// Encodes a YAML mapping to go at an indentation in a document,
+// with the number of spaces the document indents by.
+func getYamlBlock(value orderedObject, indent int, spaces int) string {
+	block := ""
+	for _, line := range strings.SplitAfter(encodeYamlWithIndent(value, spaces), "\n") {
+		if strings.TrimSpace(line) != "" {
+			line = strings.Repeat(" ", indent) + line
+		}
+
+		block += line
+	}
+
+	return block
+}
+
+// Replaces the lines from start up to end with text.
+func spliceLines(lines []string, start int, end int, text string) []byte {
+	before := strings.Join(lines[:start], "")
+	if before != "" && !strings.HasSuffix(before, "\n") {
+		before += "\n"
+	}
+
+	return []byte(before + text + strings.Join(lines[end:], ""))
+}
+
+func mergeYamlSchemas(document []byte, schemas orderedObject) ([]byte, error) {
+	var root yaml.Node
+	err := yaml.Unmarshal(document, &root)
+	if err != nil {
+		return nil, err
+	}
+
+	if root.Kind != yaml.DocumentNode || root.Content[0].Kind != yaml.MappingNode {
+		return nil, NotAnObject
+	}
+
+	lines := strings.SplitAfter(string(document), "\n")
+	section := orderedObject{{"schemas", schemas}}
+
+	top := root.Content[0]
+	spaces := getYamlIndent(top)
+
+	//
+	// `{}`, which only has the schemas once it is replaced.
+	//
+	if len(top.Content) == 0 {
+		return spliceLines(lines, top.Line-1, top.Line, getYamlBlock(orderedObject{{"components", section}}, 0, spaces)), nil
+	}
+
+	if top.Style == yaml.FlowStyle {
+		return nil, FlowDocument
+	}
+
+	index := getMappingIndex(top, "components")
+	if index == -1 {
+		return spliceLines(lines, len(lines), len(lines), getYamlBlock(orderedObject{{"components", section}}, top.Content[0].Column-1, spaces)), nil
+	}
+
+	key, components := top.Content[index], top.Content[index+1]
+	keyLine := key.Line - 1
+
+	//
+	// `components:` without anything in it, or `components: {}`.
+	//
+	if len(components.Content) == 0 && (components.Tag == "!!null" || components.Kind == yaml.MappingNode) {
+		end := getBlockEnd(lines, keyLine, key.Column-1)
+		return spliceLines(lines, keyLine, end, getYamlBlock(orderedObject{{"components", section}}, key.Column-1, spaces)), nil
+	}
+
+	if components.Kind != yaml.MappingNode || components.Style == yaml.FlowStyle {
+		return nil, ComponentsNotAnObject
+	}
+
+	index = getMappingIndex(components, "schemas")
+	if index == -1 {
+		end := getBlockEnd(lines, keyLine, key.Column-1)
+		return spliceLines(lines, end, end, getYamlBlock(section, components.Content[0].Column-1, spaces)), nil
+	}
+
+	key = components.Content[index]
+	keyLine = key.Line - 1
+
+	return spliceLines(lines, keyLine, getBlockEnd(lines, keyLine, key.Column-1), getYamlBlock(section, key.Column-1, spaces)), nil
+}
+
+// A key of a JSON object, with where its value is in the document.
+type jsonMember struct {
+	Key        string
+	Start, End int
+}
+
+// The members of the JSON object in document from offset.
+func getJsonMembers(document []byte, offset int) ([]jsonMember, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document[offset:]))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if token != json.Delim('{') {
+		return nil, NotAnObject
+	}
+
+	members := make([]jsonMember, 0)
+
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var value json.RawMessage
+		err = decoder.Decode(&value)
+		if err != nil {
+			return nil, err
+		}
+
+		end := offset + int(decoder.InputOffset())
+		members = append(members, jsonMember{Key: key.(string), Start: end - len(value), End: end})
+	}
+
+	return members, nil
+}
+
+func getJsonMember(members []jsonMember, key string) (jsonMember, bool) {
+	for _, member := range members {
+		if member.Key == key {
+			return member, true
+		}
+	}
+
+	return jsonMember{}, false
+}
+
+// The spaces the line of an offset starts with.
+func getLineIndent(document []byte, offset int) string {
+	start := bytes.LastIndexByte(document[:offset], '\n') + 1
+	line := document[start:offset]
+
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+/*
+ * A JSON document the schemas are written in, indented like it is,
+ * or on one line where what they go in is on one line.
+ */
+type jsonDocument struct {
+	text   []byte
+	indent string
+}
+
+// Encodes a value to go at an offset of the document.
+func (d jsonDocument) encode(offset int, value any, multiline bool) string {
+	if !multiline {
+		output, err := json.Marshal(value)
+		if err != nil {
+			panic(err)
+		}
+
+		return string(output)
+	}
+
+	output, err := json.MarshalIndent(value, getLineIndent(d.text, offset), d.indent)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(output)
+}
+
+// Replaces the text from start up to end with a value.
+func (d jsonDocument) replace(start int, end int, value any, multiline bool) []byte {
+	output := append([]byte{}, d.text[:start]...)
+	output = append(output, d.encode(start, value, multiline)...)
+
+	return append(output, d.text[end:]...)
+}
+
+// Adds a member after the others of the object from start to end.
+func (d jsonDocument) addMember(start int, end int, members []jsonMember, key string, value any) []byte {
+	multiline := bytes.ContainsRune(d.text[start:end], '\n')
+	quotedKey, _ := json.Marshal(key)
+
+	offset := start + 1
+	member := string(quotedKey) + ": "
+
+	if len(members) > 0 {
+		last := members[len(members)-1]
+		offset = last.End
+
+		if multiline {
+			member = ",\n" + getLineIndent(d.text, last.Start) + member
+		} else {
+			member = ", " + member
+		}
+	} else if multiline {
+		member = "\n" + getLineIndent(d.text, start) + d.indent + member
+	}
+
+	text := append([]byte{}, d.text[:offset]...)
+	text = append(text, member...)
+
+	return jsonDocument{text: append(text, d.text[offset:]...), indent: d.indent}.replace(len(text), len(text), value, multiline)
+}
+
+func mergeJsonSchemas(document []byte, schemas orderedObject) ([]byte, error) {
+	members, err := getJsonMembers(document, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	d := jsonDocument{text: document, indent: "  "}
+	if len(members) > 0 && getLineIndent(document, members[0].Start) != "" {
+		d.indent = getLineIndent(document, members[0].Start)
+	}
+
+	components, exists := getJsonMember(members, "components")
+	if !exists {
+		start := bytes.IndexByte(document, '{')
+		end := len(bytes.TrimRight(document, " \t\r\n"))
+
+		return d.addMember(start, end, members, "components", orderedObject{{"schemas", schemas}}), nil
+	}
+
+	componentMembers, err := getJsonMembers(document, components.Start)
+	if err == NotAnObject {
+		return nil, ComponentsNotAnObject
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	oldSchemas, exists := getJsonMember(componentMembers, "schemas")
+	if !exists {
+		return d.addMember(components.Start, components.End, componentMembers, "schemas", schemas), nil
+	}
+
+	multiline := bytes.ContainsRune(document[components.Start:components.End], '\n')
+	return d.replace(oldSchemas.Start, oldSchemas.End, schemas, multiline), nil
+}
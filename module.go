@@ -0,0 +1,217 @@
+package gobridge
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// Reads go.mod, and adds its module and the modules it replaces
+// with a local directory, such as `replace example.com/shared => ../shared`.
+func addModule(modules Modules, rootDir string, moduleDir string) error {
+	goModPath := filepath.Join(moduleDir, "go.mod")
+
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		return err
+	}
+
+	file, err := modfile.Parse(goModPath, content, nil)
+	if err != nil {
+		return err
+	}
+
+	if file.Module == nil {
+		return errors.New("Could not find the module path in " + goModPath)
+	}
+
+	modules[file.Module.Mod.Path], err = getModuleDir(rootDir, moduleDir)
+	if err != nil {
+		return err
+	}
+
+	for _, replace := range file.Replace {
+		err = addLocalReplace(modules, rootDir, moduleDir, replace)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Replacements with a version are downloaded modules, not part of the project.
+func addLocalReplace(modules Modules, rootDir string, fileDir string, replace *modfile.Replace) error {
+	if replace.New.Version != "" || !modfile.IsDirectoryPath(replace.New.Path) {
+		return nil
+	}
+
+	replaceDir := replace.New.Path
+	if !filepath.IsAbs(replaceDir) {
+		replaceDir = filepath.Join(fileDir, replaceDir)
+	}
+
+	moduleDir, err := getModuleDir(rootDir, replaceDir)
+	if err != nil {
+		return err
+	}
+
+	modules[replace.Old.Path] = moduleDir
+	return nil
+}
+
+// Unlike packages, modules can be outside of the root, such as `replace ../shared`.
+func getModuleDir(rootDir string, moduleDir string) (string, error) {
+	relativeDir, err := filepath.Rel(rootDir, moduleDir)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.ToSlash(relativeDir), nil
+}
+
+// Finds go.work the same way the go command does,
+// including `GOWORK=off` to ignore it.
+func findWorkFile(moduleRoot string) string {
+	goWork := os.Getenv("GOWORK")
+	if goWork == "off" {
+		return ""
+	}
+
+	if goWork != "" {
+		return goWork
+	}
+
+	dir := moduleRoot
+	for {
+		workPath := filepath.Join(dir, "go.work")
+
+		_, err := os.Stat(workPath)
+		if err == nil {
+			return workPath
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+
+		dir = parent
+	}
+}
+
+// Finds every module whose packages are part of the project: the module at
+// moduleRoot, every module used by go.work, and modules replaced with a local directory.
+//
+// Returns the project root, which is the directory of go.work in a workspace,
+// as every package path is relative to it.
+func LoadModules(moduleRoot string) (string, Modules, error) {
+	moduleRoot, err := filepath.Abs(moduleRoot)
+	if err != nil {
+		return "", nil, err
+	}
+
+	modules := make(Modules)
+
+	workPath := findWorkFile(moduleRoot)
+	if workPath == "" {
+		err := addModule(modules, moduleRoot, moduleRoot)
+		return moduleRoot, modules, err
+	}
+
+	workPath, err = filepath.Abs(workPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	content, err := os.ReadFile(workPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	workFile, err := modfile.ParseWork(workPath, content, nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rootDir := filepath.Dir(workPath)
+	isUsed := false
+
+	for _, use := range workFile.Use {
+		moduleDir := use.Path
+		if !filepath.IsAbs(moduleDir) {
+			moduleDir = filepath.Join(rootDir, moduleDir)
+		}
+
+		if moduleDir == moduleRoot {
+			isUsed = true
+		}
+
+		err := addModule(modules, rootDir, moduleDir)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	if !isUsed {
+		return "", nil, errors.New(moduleRoot + " is not one of the modules used by " + workPath)
+	}
+
+	//
+	// Replacements in go.work win over the ones in go.mod.
+	//
+	for _, replace := range workFile.Replace {
+		err := addLocalReplace(modules, rootDir, rootDir, replace)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	return rootDir, modules, nil
+}
+
+// Walks up from dir until it finds go.mod, the same way the go command does.
+func FindModuleRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		_, err := os.Stat(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("Could not find go.mod in this directory or any of its parents")
+		}
+
+		dir = parent
+	}
+}
+
+// The directory of a package relative to the project root,
+// which is how the parser knows packages.
+func GetPackagePath(rootDir string, dir string) (string, error) {
+	absoluteRoot, err := filepath.Abs(rootDir)
+	if err != nil {
+		return "", err
+	}
+
+	absoluteDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	packagePath, err := filepath.Rel(absoluteRoot, absoluteDir)
+	if err != nil || packagePath == ".." || strings.HasPrefix(packagePath, "../") {
+		return "", errors.New(dir + " is not inside the project at " + rootDir)
+	}
+
+	return filepath.ToSlash(packagePath), nil
+}
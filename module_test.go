@@ -0,0 +1,182 @@
+package gobridge
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(path, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestLoadModules(t *testing.T) {
+	t.Setenv("GOWORK", "")
+
+	dir := writeFiles(t, map[string]string{
+		"app/go.mod": `// module example.com/wrong
+module "example.com/app" // the real one
+
+go 1.22
+
+require example.com/shared v0.0.0
+
+replace example.com/shared => ../shared
+
+replace example.com/remote => example.com/fork v1.2.3
+`,
+		"shared/go.mod": "module example.com/shared\n",
+	})
+
+	rootDir, modules, err := LoadModules(filepath.Join(dir, "app"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	expected := Modules{"example.com/app": ".", "example.com/shared": "../shared"}
+	if rootDir != filepath.Join(dir, "app") || !maps.Equal(modules, expected) {
+		t.Log(rootDir, modules)
+		t.FailNow()
+	}
+}
+
+func TestLoadWorkspaceModules(t *testing.T) {
+	t.Setenv("GOWORK", "")
+
+	dir := writeFiles(t, map[string]string{
+		"go.work": `go 1.22
+
+use (
+	./api
+	./shared
+)
+
+replace example.com/local => ./local
+`,
+		"api/go.mod":    "module example.com/api\n",
+		"shared/go.mod": "module example.com/shared\n",
+	})
+
+	rootDir, modules, err := LoadModules(filepath.Join(dir, "api"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	expected := Modules{"example.com/api": "api", "example.com/shared": "shared", "example.com/local": "local"}
+	if rootDir != dir || !maps.Equal(modules, expected) {
+		t.Log(rootDir, modules)
+		t.FailNow()
+	}
+
+	t.Setenv("GOWORK", "off")
+
+	rootDir, modules, err = LoadModules(filepath.Join(dir, "api"))
+	if err != nil || rootDir != filepath.Join(dir, "api") || len(modules) != 1 {
+		t.Log(rootDir, modules, err)
+		t.FailNow()
+	}
+}
+
+func TestLoadAbsoluteWorkspaceModules(t *testing.T) {
+	t.Setenv("GOWORK", "")
+
+	sharedDir := writeFiles(t, map[string]string{
+		"go.mod": "module example.com/shared\n",
+	})
+
+	dir := writeFiles(t, map[string]string{
+		"go.work":    "go 1.22\n\nuse (\n\t./api\n\t" + sharedDir + "\n)\n",
+		"api/go.mod": "module example.com/api\n",
+	})
+
+	rootDir, modules, err := LoadModules(filepath.Join(dir, "api"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	relativeDir, err := filepath.Rel(dir, sharedDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Modules{"example.com/api": "api", "example.com/shared": relativeDir}
+	if rootDir != dir || !maps.Equal(modules, expected) {
+		t.Log(rootDir, modules)
+		t.FailNow()
+	}
+}
+
+func TestImportPathDir(t *testing.T) {
+	p := newParserWithCache(NewPackageCache(".", Modules{
+		"example.com/api":        "api",
+		"example.com/api/nested": "nested",
+		"example.com/shared":     "../shared",
+	}))
+
+	tests := map[string]string{
+		"example.com/api":             "api",
+		"example.com/api/models":      "api/models",
+		"example.com/api/nested/deep": "nested/deep",
+		"example.com/shared/models":   "../shared/models",
+	}
+
+	for importPath, expected := range tests {
+		dir, isLocal := p.getImportPathDir(importPath)
+		if !isLocal || dir != expected {
+			t.Log(importPath, dir)
+			t.FailNow()
+		}
+	}
+
+	_, isLocal := p.getImportPathDir("example.com/apiary")
+	if isLocal {
+		t.Log("Modules should only match whole path elements")
+		t.FailNow()
+	}
+}
+
+func TestFindModuleRoot(t *testing.T) {
+	root, err := FindModuleRoot("testdata/test10/nested")
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	cwd, _ := os.Getwd()
+	if root != cwd {
+		t.Log(root)
+		t.FailNow()
+	}
+
+	packagePath, err := GetPackagePath(root, "testdata/test10/nested")
+	if err != nil || packagePath != "testdata/test10/nested" {
+		t.Log(packagePath, err)
+		t.FailNow()
+	}
+
+	_, err = GetPackagePath("testdata/test10", "testdata/test9")
+	if err == nil {
+		t.Log("Packages outside of the root should fail")
+		t.FailNow()
+	}
+}
@@ -0,0 +1,75 @@
+package gobridge
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OPENAPI_TARGET      = "openapi"
+	OPENAPI_JSON_TARGET = "openapi-json"
+)
+
+const OPENAPI_SCHEMA_REF = "#/components/schemas/"
+
+/*
+ * components.schemas of an OpenAPI 3.1 document, which are JSON Schema,
+ * so they are the schemas of the jsonschema target with their own $ref.
+ */
+type openapiGenerator struct {
+	*jsonSchemaGenerator
+
+	json bool
+}
+
+func newOpenAPIGenerator(structList StructList, options Options) Generator {
+	return &openapiGenerator{
+		jsonSchemaGenerator: newJsonSchemaGeneratorWithRefs(structList, options, OPENAPI_SCHEMA_REF),
+	}
+}
+
+func newOpenAPIJsonGenerator(structList StructList, options Options) Generator {
+	g := newOpenAPIGenerator(structList, options).(*openapiGenerator)
+	g.json = true
+
+	return g
+}
+
+// JSON has no comments, so it is left without the header.
+func (g *openapiGenerator) Comment(line string) string {
+	if g.json {
+		return ""
+	}
+
+	return "# " + line
+}
+
+func (g *openapiGenerator) Footer(structList StructList) string {
+	fragment := orderedObject{{"components", orderedObject{{"schemas", g.schemas}}}}
+
+	if g.json {
+		return encodeJson(fragment)
+	}
+
+	return encodeYaml(fragment)
+}
+
+func encodeYaml(value any) string {
+	return encodeYamlWithIndent(value, 2)
+}
+
+func encodeYamlWithIndent(value any, spaces int) string {
+	buffer := bytes.Buffer{}
+
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(spaces)
+
+	err := encoder.Encode(value)
+	if err != nil {
+		panic(err)
+	}
+
+	encoder.Close()
+	return buffer.String()
+}
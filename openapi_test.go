@@ -0,0 +1,370 @@
+package gobridge
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOpenAPI(t *testing.T) {
+	structs := getCodeStructs(t, modelsCode)
+
+	output, err := Generate(OPENAPI_TARGET, structs, Options{IntegerChecks: true, Header: "Generated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# Generated
+components:
+  schemas:
+    Dog:
+      type: object
+      properties:
+        barks:
+          type: boolean
+      required:
+        - barks
+    Cat:
+      type: object
+      properties: {}
+    Friend:
+      type: object
+      properties:
+        Best:
+          $ref: '#/components/schemas/Friend'
+        pet:
+          oneOf:
+            - $ref: '#/components/schemas/Dog'
+              properties:
+                kind:
+                  const: dog
+              required:
+                - kind
+            - $ref: '#/components/schemas/Cat'
+              properties:
+                kind:
+                  const: cat
+              required:
+                - kind
+          discriminator:
+            propertyName: kind
+            mapping:
+              dog: '#/components/schemas/Dog'
+              cat: '#/components/schemas/Cat'
+      required:
+        - Best
+        - pet
+    User:
+      type: object
+      description: Somebody using the app.
+      properties:
+        id:
+          type: integer
+          minimum: 0
+          maximum: 255
+        name:
+          type: string
+        friends:
+          type:
+            - array
+            - "null"
+          items:
+            $ref: '#/components/schemas/Friend'
+          description: Who they follow.
+        scores:
+          type:
+            - object
+            - "null"
+          propertyNames:
+            pattern: ^-?\d+$
+          additionalProperties:
+            type: number
+            format: double
+        from:
+          type: array
+          items:
+            type: string
+          minItems: 2
+          maxItems: 2
+        avatar:
+          type:
+            - string
+            - "null"
+          contentEncoding: base64
+        extra: {}
+        address:
+          type: object
+          properties:
+            street:
+              type: string
+          required:
+            - street
+      required:
+        - id
+        - friends
+        - scores
+        - from
+        - avatar
+        - extra
+        - address
+`
+
+	if output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+
+	output, err = Generate(OPENAPI_JSON_TARGET, structs, Options{Header: "Generated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fragment map[string]map[string]map[string]any
+	err = json.Unmarshal([]byte(output), &fragment)
+	if err != nil {
+		t.Log(output)
+		t.Fatal(err)
+	}
+
+	if len(fragment["components"]["schemas"]) != 4 {
+		t.Log(output)
+		t.FailNow()
+	}
+}
+
+// encoding/json writes nil slices, maps and []byte as null, but never a fixed array.
+func TestOpenAPINullableCollections(t *testing.T) {
+	structs := getCodeStructs(t, `
+package types
+
+type A struct {
+  List  []string
+  Map   map[string]int
+  Bytes []byte
+  Fixed [3]int
+  Tuple [2][]int
+}
+`)
+
+	output, err := Generate(OPENAPI_JSON_TARGET, structs, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fragment struct {
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]struct {
+					Type  any
+					Items struct {
+						Type any
+					}
+				}
+			}
+		}
+	}
+
+	err = json.Unmarshal([]byte(output), &fragment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"List":  `["array","null"]`,
+		"Map":   `["object","null"]`,
+		"Bytes": `["string","null"]`,
+		"Fixed": `"array"`,
+		"Tuple": `"array"`,
+	}
+
+	properties := fragment.Components.Schemas["A"].Properties
+	for name, expectedType := range expected {
+		schemaType, _ := json.Marshal(properties[name].Type)
+		if string(schemaType) != expectedType {
+			t.Log(name, string(schemaType))
+			t.FailNow()
+		}
+	}
+
+	itemsType, _ := json.Marshal(properties["Tuple"].Items.Type)
+	if string(itemsType) != `["array","null"]` {
+		t.Log(output)
+		t.FailNow()
+	}
+}
+
+func TestMergeOpenAPI(t *testing.T) {
+	structs := getCodeStructs(t, "package types\n\ntype A struct {\n  B bool\n}\n")
+
+	//
+	// Everything but the schemas is kept as it was written,
+	// even what encoding it again would change, such as 1.0 and the quotes.
+	//
+	tests := map[string][2]string{
+		"YAML": {`openapi: 3.1.0
+# Kept as it is.
+info:
+    title: 'App'
+    version: 1.0   # not "1"
+components:
+    # The old schemas go.
+    schemas:
+        Old:
+            type: string
+
+    responses:
+        NotFound: {description: Missing}
+`, `openapi: 3.1.0
+# Kept as it is.
+info:
+    title: 'App'
+    version: 1.0   # not "1"
+components:
+    # The old schemas go.
+    schemas:
+        A:
+            type: object
+            properties:
+                B:
+                    type: boolean
+            required:
+                - B
+
+    responses:
+        NotFound: {description: Missing}
+`},
+		"YAML without schemas": {`openapi: 3.1.0
+components:
+  responses:
+    NotFound:
+      description: Missing
+paths: {}
+`, `openapi: 3.1.0
+components:
+  responses:
+    NotFound:
+      description: Missing
+  schemas:
+    A:
+      type: object
+      properties:
+        B:
+          type: boolean
+      required:
+        - B
+paths: {}
+`},
+		"YAML without components": {"openapi: 3.1.0\npaths: {}", `openapi: 3.1.0
+paths: {}
+components:
+  schemas:
+    A:
+      type: object
+      properties:
+        B:
+          type: boolean
+      required:
+        - B
+`},
+		"YAML with empty components": {"openapi: 3.1.0\ncomponents:\npaths: {}\n", `openapi: 3.1.0
+components:
+  schemas:
+    A:
+      type: object
+      properties:
+        B:
+          type: boolean
+      required:
+        - B
+paths: {}
+`},
+		"YAML with an empty document": {"# api\n{}\n", `# api
+components:
+  schemas:
+    A:
+      type: object
+      properties:
+        B:
+          type: boolean
+      required:
+        - B
+`},
+		"JSON": {`{
+    "openapi": "3.1.0",
+    "info": { "title": "App", "version": 1.0 },
+    "components": {
+        "schemas": {
+            "Old": { "type": "string" }
+        },
+        "responses": {}
+    }
+}
+`, `{
+    "openapi": "3.1.0",
+    "info": { "title": "App", "version": 1.0 },
+    "components": {
+        "schemas": {
+            "A": {
+                "type": "object",
+                "properties": {
+                    "B": {
+                        "type": "boolean"
+                    }
+                },
+                "required": [
+                    "B"
+                ]
+            }
+        },
+        "responses": {}
+    }
+}
+`},
+		"JSON without schemas": {`{
+  "openapi": "3.1.0",
+  "components": {
+    "responses": {}
+  }
+}`, `{
+  "openapi": "3.1.0",
+  "components": {
+    "responses": {},
+    "schemas": {
+      "A": {
+        "type": "object",
+        "properties": {
+          "B": {
+            "type": "boolean"
+          }
+        },
+        "required": [
+          "B"
+        ]
+      }
+    }
+  }
+}`},
+		"JSON on one line": {`{"openapi": "3.1.0", "paths": {}}`, `{"openapi": "3.1.0", "paths": {}, "components": {"schemas":{"A":{"type":"object","properties":{"B":{"type":"boolean"}},"required":["B"]}}}}`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			output, err := MergeOpenAPI([]byte(test[0]), structs, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(output) != test[1] {
+				t.Log(string(output))
+				t.FailNow()
+			}
+		})
+	}
+
+	for _, document := range []string{"- a list", "components: 3", `{"components": []}`, `[]`, "# api\n{openapi: 3.1.0}\n"} {
+		_, err := MergeOpenAPI([]byte(document), structs, Options{})
+		if err == nil {
+			t.Log(document)
+			t.FailNow()
+		}
+	}
+}
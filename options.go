@@ -0,0 +1,65 @@
+package gobridge
+
+// How int64 and uint64 fields are represented, as they can
+// exceed JavaScript's safe integer range.
+type Int64Mode string
+
+const (
+	Int64AsNumber Int64Mode = "number"
+	Int64AsBigInt Int64Mode = "bigint"
+	Int64AsString Int64Mode = "string"
+)
+
+type Options struct {
+	Int64 Int64Mode
+
+	/* Adds integer() and min/max checks derived from the golang type width */
+	IntegerChecks bool
+
+	// Schemas used instead of types we can't look into, such as
+	// `time.Time` or types with a MarshalJSON method.
+	//
+	// Map: PackageName.TypeName -> Schema
+	Overrides map[string]string
+
+	// Unions for interfaces that can't have a gobridge:union directive,
+	// such as ones shared with code we don't want to add comments to.
+	//
+	// Map: PackageName.TypeName -> what follows gobridge:union, such as `kind Circle=circle Square`
+	Unions map[string]string
+
+	// Added around every schema name, such as `UserSchema`
+	// with the suffix `Schema`.
+	NamePrefix string
+	NameSuffix string
+
+	/* Called with problems that don't stop the output from being generated */
+	Warn func(message string)
+
+	// When set, every field we can't parse is passed to it and becomes unknown(),
+	// instead of stopping at the first one.
+	Error func(err error)
+
+	/* Lines written at the top of the output, as comments of the target */
+	Header string
+
+	// The field numbers of the proto target, new fields are added to it.
+	// Fields are numbered from 1 when it is nil.
+	FieldNumbers FieldNumbers
+}
+
+// Reads the int64 mode as it is written in flags and config files.
+func ParseInt64Mode(mode string) (Int64Mode, bool) {
+	switch Int64Mode(mode) {
+	case Int64AsNumber, Int64AsBigInt, Int64AsString:
+		return Int64Mode(mode), true
+	}
+
+	return "", false
+}
+
+func (o Options) warn(message string) {
+	if o.Warn != nil {
+		o.Warn(message)
+	}
+}
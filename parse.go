@@ -0,0 +1,116 @@
+package gobridge
+
+import (
+	"go/parser"
+	"path/filepath"
+)
+
+// Reads the entry packages, which are directories relative to the root of the cache,
+// and parses the structs they need, in order.
+// Without types, every struct of the entry packages is parsed.
+//
+// Also returns the directories of every package that was read,
+// which are the packages the output depends on.
+func Load(cache *PackageCache, entries []string, types []string, options Options) (StructList, []string, error) {
+	p := newParserWithCache(cache)
+	p.report = options.Error
+	p.unions = options.Unions
+
+	pkgs := make([]*Package, 0, len(entries))
+	for _, entry := range entries {
+		pkg, err := p.loadPackage(filepath.Clean(entry))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		pkgs = append(pkgs, pkg)
+	}
+
+	if len(pkgs) > 0 {
+		p.entryPackage = pkgs[0].Name
+	}
+
+	err := p.queueRootTypes(pkgs, types)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	structs, err := p.Parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	structs, err = OrderStructs(structs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return structs, p.packageDirs(), nil
+}
+
+func MainParse(entryFile string, givenProjectPath string) (string, error) {
+	return MainParseWithOptions(entryFile, givenProjectPath, Options{})
+}
+
+func MainParseWithOptions(entryFile string, givenProjectPath string, options Options) (string, error) {
+	parser, err := ParserFactory(entryFile, givenProjectPath)
+	if err != nil {
+		return "", err
+	}
+
+	parser.report = options.Error
+	parser.unions = options.Unions
+
+	return parseQueued(&parser, options)
+}
+
+// Parses everything queued, and generates the schemas.
+func parseQueued(p *Parser, options Options) (string, error) {
+	structs, err := p.Parse()
+	if err != nil {
+		return "", err
+	}
+
+	structs, err = OrderStructs(structs)
+	if err != nil {
+		return "", err
+	}
+
+	return GenerateValibot(structs, options)
+}
+
+func CodeParse(content string) (string, error) {
+	return CodeParseWithOptions(content, Options{})
+}
+
+func CodeParseWithOptions(content string, options Options) (string, error) {
+	structs, err := LoadCode(content, options)
+	if err != nil {
+		return "", err
+	}
+
+	return GenerateValibot(structs, options)
+}
+
+// The structs of a single file of go code, in order, without touching the filesystem.
+// Structs from other packages can't be found, so fields using them are unknown, like `time.Time`.
+func LoadCode(content string, options Options) (StructList, error) {
+	p := newParser("")
+	p.report = options.Error
+	p.unions = options.Unions
+
+	astFile, err := parser.ParseFile(p.fileSet, "", content, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	p.entryPackage = p.consumeFile(astFile, astFile.Name.Name)
+	p.queuePackage(p.packages[astFile.Name.Name])
+
+	structs, err := p.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return OrderStructs(structs)
+}
@@ -1,6 +1,9 @@
-package main
+package gobridge
 
-import "testing"
+import (
+	"path/filepath"
+	"testing"
+)
 
 func TestSamePackage(t *testing.T) {
 	valibotString, err := MainParse("./test/test1/a.go", "github.com/JohnCosta27/go-bridge")
@@ -102,7 +105,7 @@ const IAmNested = object({
 });
 
 const WithMap = object({
-  Map: record(IAmNested),
+  Map: record(string(), IAmNested),
 });
 `
 
@@ -227,7 +230,7 @@ func TestEmbeddedDeps(t *testing.T) {
 	valibotString, err := MainParse("./test/test8/a.go", "github.com/JohnCosta27/go-bridge")
 
 	valibotValidator := `
-import { object, string, number } from 'valibot';
+import { object, string, number, record, array } from 'valibot';
 
 const A = object({
   NormalField: string(),
@@ -236,14 +239,14 @@ const A = object({
   MyStruct: object({
     Hello: string(),
     World: number(),
-    MyNestedStruct: object({
-      Hello: string(),
-      World: number(),
-    }),
-    MyAnonArrayStruct: object({
-      Hello: string(),
-      World: number(),
-    }),
+    MyNestedStruct: record(string(), object({
+        Hello: string(),
+        World: number(),
+      })),
+    MyAnonArrayStruct: array(object({
+        Hello: string(),
+        World: number(),
+      })),
   }),
 });
 
@@ -265,3 +268,257 @@ const B = object({
 		t.FailNow()
 	}
 }
+
+func TestDependencyMapKeys(t *testing.T) {
+	valibotString, err := MainParse("./testdata/test9/a.go", "github.com/JohnCosta27/go-bridge")
+
+	valibotValidator := `
+import { object, record, pipe, string, regex, number } from 'valibot';
+
+const Lookup = object({
+  ByID: record(pipe(string(), regex(/^-?\d+$/)), string()),
+  ByName: record(string(), number()),
+});
+`
+
+	t.Log(valibotString)
+
+	if err != nil {
+		t.Log("Error is not null")
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if valibotString != valibotValidator {
+		t.FailNow()
+	}
+}
+
+func TestEmbeddedDependencyPackages(t *testing.T) {
+	valibotString, err := MainParse("./testdata/test10/a.go", "github.com/JohnCosta27/go-bridge")
+
+	valibotValidator := `
+import { object, number, string, boolean } from 'valibot';
+
+const Other = object({
+  X: number(),
+});
+
+const A = object({
+  ID: string(),
+  CreatedBy: string(),
+  Name: string(),
+  Other: Other,
+});
+
+const Base = object({
+  ID: string(),
+});
+
+const B = object({
+  ID: string(),
+  CreatedBy: string(),
+  Name: string(),
+  Other: Other,
+  Base: Base,
+  Extra: boolean(),
+});
+
+const Audited = object({
+  ID: string(),
+  CreatedBy: string(),
+});
+`
+
+	t.Log(valibotString)
+
+	if err != nil {
+		t.Log("Error is not null")
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if valibotString != valibotValidator {
+		t.FailNow()
+	}
+}
+
+func TestDependencyUnion(t *testing.T) {
+	valibotString, err := MainParse("./testdata/test11/a.go", "github.com/JohnCosta27/go-bridge")
+
+	valibotValidator := `
+import { object, string, number, variant, literal } from 'valibot';
+
+const Circle = object({
+  kind: string(),
+  Radius: number(),
+});
+
+const Triangle = object({
+  kind: string(),
+  Base: number(),
+});
+
+const Drawing = object({
+  Main: variant("kind", [object({ ...Circle.entries, kind: literal("circle") }), object({ ...Triangle.entries, kind: literal("triangle") })]),
+});
+`
+
+	t.Log(valibotString)
+
+	if err != nil {
+		t.Log("Error is not null")
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if valibotString != valibotValidator {
+		t.FailNow()
+	}
+}
+
+func TestErrorFilePositions(t *testing.T) {
+	_, err := MainParse("./testdata/test12/main.go", "github.com/JohnCosta27/go-bridge")
+
+	expected := "testdata/test12/main.go:4:2: Could not find struct Missing in package testdata/test12 (field B of struct A)"
+	if err == nil || err.Error() != expected {
+		t.Log(err)
+		t.FailNow()
+	}
+}
+
+// Like a job of the CLI, with the directories of the packages it used.
+func loadValibot(cache *PackageCache, entries []string, types []string, options Options) (string, []string, error) {
+	structs, packageDirs, err := Load(cache, entries, types, options)
+	if err != nil {
+		return "", nil, err
+	}
+
+	valibotString, err := GenerateValibot(structs, options)
+	return valibotString, packageDirs, err
+}
+
+func TestJobsShareCache(t *testing.T) {
+	cache := NewPackageCache(".", Modules{"github.com/JohnCosta27/go-bridge": "."})
+
+	valibotString, packageDirs, err := loadValibot(cache, []string{"./testdata/test10"}, []string{"A"}, Options{NameSuffix: "Schema"})
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	valibotValidator := `
+import { object, number, string } from 'valibot';
+
+const OtherSchema = object({
+  X: number(),
+});
+
+const ASchema = object({
+  ID: string(),
+  CreatedBy: string(),
+  Name: string(),
+  Other: OtherSchema,
+});
+
+const BaseSchema = object({
+  ID: string(),
+});
+
+const AuditedSchema = object({
+  ID: string(),
+  CreatedBy: string(),
+});
+`
+
+	if valibotString != valibotValidator {
+		t.Log(valibotString)
+		t.FailNow()
+	}
+
+	if len(packageDirs) != 2 || packageDirs[0] != "testdata/test10" || packageDirs[1] != "testdata/test10/nested" {
+		t.Log(packageDirs)
+		t.FailNow()
+	}
+
+	//
+	// The second job only uses the nested package,
+	// which is already in the cache.
+	//
+	_, packageDirs, err = loadValibot(cache, []string{"testdata/test10/nested"}, []string{"Base"}, Options{})
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if len(packageDirs) != 1 || len(cache.packages) != 2 {
+		t.Log(packageDirs, cache.packages)
+		t.FailNow()
+	}
+
+	_, _, err = loadValibot(cache, []string{"testdata/test10/nested"}, []string{"Missing"}, Options{})
+	if err == nil {
+		t.Log("Missing root types should fail")
+		t.FailNow()
+	}
+}
+
+func TestWorkspaceDependency(t *testing.T) {
+	t.Setenv("GOWORK", "")
+
+	dir := writeFiles(t, map[string]string{
+		"go.work":       "go 1.22\n\nuse (\n\t./api\n\t./shared\n)\n",
+		"api/go.mod":    "module example.com/api\n",
+		"shared/go.mod": "module example.com/shared\n",
+		"api/models/user.go": `package models
+
+import "example.com/shared/types"
+
+type User struct {
+	Name    string
+	Address types.Address
+}
+`,
+		"shared/types/address.go": `package types
+
+type Address struct {
+	Street string
+}
+`,
+	})
+
+	rootDir, modules, err := LoadModules(filepath.Join(dir, "api"))
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	valibotString, packageDirs, err := loadValibot(NewPackageCache(rootDir, modules), []string{"api/models"}, nil, Options{})
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	valibotValidator := `
+import { object, string } from 'valibot';
+
+const Address = object({
+  Street: string(),
+});
+
+const User = object({
+  Name: string(),
+  Address: Address,
+});
+`
+
+	if valibotString != valibotValidator {
+		t.Log(valibotString)
+		t.FailNow()
+	}
+
+	if len(packageDirs) != 2 || packageDirs[1] != "shared/types" {
+		t.Log(packageDirs)
+		t.FailNow()
+	}
+}
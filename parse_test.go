@@ -1,6 +1,9 @@
-package main
+package gobridge
 
-import "testing"
+import (
+	"errors"
+	"testing"
+)
 
 func TestBasicParseSimpleStruct1(t *testing.T) {
 	simpleStruct := `
@@ -322,12 +325,10 @@ type D struct {
 `
 
 	valibotValidator := `
-import { object, number, string, boolean } from 'valibot';
+import { object, number, boolean, string } from 'valibot';
 
 const A = object({
   Hello: number(),
-  FieldD: string(),
-  MyNum: number(),
   MyField: boolean(),
   World: string(),
   FieldD: string(),
@@ -450,7 +451,7 @@ type ForMap struct {
 import { object, record, string } from 'valibot';
 
 const ForMap = object({
-  Hello: record(string()),
+  Hello: record(string(), string()),
 });
 `
 
@@ -482,14 +483,14 @@ type ForMap struct {
 `
 
 	valibotValidator := `
-import { object, number, record } from 'valibot';
+import { object, number, record, string } from 'valibot';
 
 const A = object({
   Field: number(),
 });
 
 const ForMap = object({
-  Hello: record(A),
+  Hello: record(string(), A),
 });
 `
 
@@ -517,10 +518,10 @@ type ForMap struct {
 `
 
 	valibotValidator := `
-import { object, record, array, string } from 'valibot';
+import { object, record, string, array } from 'valibot';
 
 const ForMap = object({
-  Hello: record(array(string())),
+  Hello: record(string(), array(string())),
 });
 `
 
@@ -553,15 +554,15 @@ type BigType struct {
 `
 
 	valibotValidator := `
-import { object, record, array, string } from 'valibot';
+import { object, record, string, array } from 'valibot';
 
 const ForMap = object({
-  Hello: record(array(string())),
+  Hello: record(string(), array(string())),
 });
 
 const BigType = object({
-  A: array(record(ForMap)),
-  B: record(record(array(array(array(record(ForMap)))))),
+  A: array(record(string(), ForMap)),
+  B: record(string(), record(string(), array(array(array(record(string(), ForMap)))))),
 });
 `
 
@@ -638,7 +639,7 @@ import { object, string, record, array, number } from 'valibot';
 const A = object({
   Hello: object({
     World: string(),
-    A: record(array(number())),
+    A: record(string(), array(number())),
     AnotherStruct: object({
       B: string(),
     }),
@@ -720,7 +721,7 @@ const A = object({
 const B = object({
   APointer: A,
   AArrayPointer: array(A),
-  AMapPointer: record(A),
+  AMapPointer: record(string(), A),
 });
 `
 
@@ -738,3 +739,750 @@ const B = object({
 		}
 	})
 }
+
+func TestFixedArrays(t *testing.T) {
+	simpleStruct := `
+package types
+
+type A struct {
+  Point [3]float64
+  Hash [32]uint8
+  Empty []string
+}
+`
+
+	valibotValidator := `
+import { object, tuple, number, pipe, array, length, string } from 'valibot';
+
+const A = object({
+  Point: tuple([number(), number(), number()]),
+  Hash: pipe(array(number()), length(32)),
+  Empty: array(string()),
+});
+`
+
+	outputParse, err := CodeParse(simpleStruct)
+	t.Log(outputParse)
+
+	if err != nil {
+		t.Log("Error is not null")
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if outputParse != valibotValidator {
+		t.FailNow()
+	}
+}
+
+func TestArrayLengthConstants(t *testing.T) {
+	simpleStruct := `
+package types
+
+import "crypto/sha256"
+
+const Size = 2
+
+const (
+  Small = iota + 1
+  Large
+)
+
+type A struct {
+  Pair [Size]string
+  Wide [Large << 1]bool
+  Hash [sha256.Size]byte
+  None [0]int
+  Data []byte
+}
+`
+
+	valibotValidator := `
+import { object, tuple, string, boolean, pipe, array, number, length, base64 } from 'valibot';
+
+const A = object({
+  Pair: tuple([string(), string()]),
+  Wide: tuple([boolean(), boolean(), boolean(), boolean()]),
+  Hash: pipe(array(number()), length(32)),
+  None: tuple([]),
+  Data: pipe(string(), base64()),
+});
+`
+
+	outputParse, err := CodeParse(simpleStruct)
+	t.Log(outputParse)
+
+	if err != nil {
+		t.Log("Error is not null")
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if outputParse != valibotValidator {
+		t.FailNow()
+	}
+}
+
+func TestAliasMapKeys(t *testing.T) {
+	simpleStruct := `
+package types
+
+type A struct {
+  ByByte map[byte]string
+  ByRune map[rune]string
+  ByPointer map[uintptr]string
+}
+`
+
+	valibotValidator := `
+import { object, record, pipe, string, regex } from 'valibot';
+
+const A = object({
+  ByByte: record(pipe(string(), regex(/^\d+$/)), string()),
+  ByRune: record(pipe(string(), regex(/^-?\d+$/)), string()),
+  ByPointer: record(pipe(string(), regex(/^\d+$/)), string()),
+});
+`
+
+	outputParse, err := CodeParse(simpleStruct)
+	t.Log(outputParse)
+
+	if err != nil {
+		t.Log("Error is not null")
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if outputParse != valibotValidator {
+		t.FailNow()
+	}
+}
+
+func TestMapKeys(t *testing.T) {
+	simpleStruct := `
+package types
+
+type ID uint32
+
+type Key struct {
+  A string
+}
+
+func (k Key) MarshalText() ([]byte, error) {
+  return []byte(k.A), nil
+}
+
+type A struct {
+  ByInt map[int]string
+  ByID map[ID]string
+  ByKey map[Key]string
+  ByKeyPointer map[*Key]string
+}
+`
+
+	valibotValidator := `
+import { object, record, pipe, string, regex } from 'valibot';
+
+const A = object({
+  ByInt: record(pipe(string(), regex(/^-?\d+$/)), string()),
+  ByID: record(pipe(string(), regex(/^\d+$/)), string()),
+  ByKey: record(string(), string()),
+  ByKeyPointer: record(string(), string()),
+});
+`
+
+	outputParse, err := CodeParse(simpleStruct)
+	t.Log(outputParse)
+
+	if err != nil {
+		t.Log("Error is not null")
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if outputParse != valibotValidator {
+		t.FailNow()
+	}
+}
+
+func TestMapKeysUnsupported(t *testing.T) {
+	t.Run("Bool keys", func(t *testing.T) {
+		simpleStruct := `
+package types
+
+type A struct {
+  ByBool map[bool]string
+}
+`
+
+		_, err := CodeParse(simpleStruct)
+		if err == nil {
+			t.Log("Expected an error for bool map keys")
+			t.FailNow()
+		}
+	})
+
+	t.Run("Struct keys without MarshalText", func(t *testing.T) {
+		simpleStruct := `
+package types
+
+type Key struct {
+  A string
+}
+
+type A struct {
+  ByKey map[Key]string
+}
+`
+
+		_, err := CodeParse(simpleStruct)
+		if err == nil {
+			t.Log("Expected an error for struct map keys")
+			t.FailNow()
+		}
+	})
+
+	t.Run("Pointer keys without MarshalText", func(t *testing.T) {
+		simpleStruct := `
+package types
+
+type A struct {
+  ByPointer map[*int]string
+}
+`
+
+		_, err := CodeParse(simpleStruct)
+		if err == nil {
+			t.Log("Expected an error for pointer map keys")
+			t.FailNow()
+		}
+	})
+}
+
+func TestStringOption(t *testing.T) {
+	simpleStruct := `
+package types
+
+type A struct {
+  ID int64 ` + "`json:\",string\"`" + `
+  Price float64 ` + "`json:\",string\"`" + `
+  Enabled bool ` + "`json:\",string\"`" + `
+  Count uint8
+}
+`
+
+	valibotValidator := `
+import { object, pipe, string, regex, transform, picklist, number } from 'valibot';
+
+const A = object({
+  ID: pipe(string(), regex(/^-?\d+$/), transform(Number)),
+  Price: pipe(string(), regex(/^-?\d+(\.\d+)?([eE][+-]?\d+)?$/), transform(Number)),
+  Enabled: pipe(picklist(['true', 'false']), transform((value) => value === 'true')),
+  Count: number(),
+});
+`
+
+	outputParse, err := CodeParse(simpleStruct)
+	t.Log(outputParse)
+
+	if err != nil {
+		t.Log("Error is not null")
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if outputParse != valibotValidator {
+		t.FailNow()
+	}
+}
+
+func TestInt64Modes(t *testing.T) {
+	simpleStruct := `
+package types
+
+type Cents int64
+
+type A struct {
+  ID uint64
+  Price Cents
+  Small int32
+}
+`
+
+	t.Run("BigInt", func(t *testing.T) {
+		valibotValidator := `
+import { object, bigint, number } from 'valibot';
+
+const A = object({
+  ID: bigint(),
+  Price: bigint(),
+  Small: number(),
+});
+`
+
+		outputParse, err := CodeParseWithOptions(simpleStruct, Options{Int64: Int64AsBigInt})
+		t.Log(outputParse)
+
+		if err != nil {
+			t.Log("Error is not null")
+			t.Log(err)
+			t.FailNow()
+		}
+
+		if outputParse != valibotValidator {
+			t.FailNow()
+		}
+	})
+
+	t.Run("String", func(t *testing.T) {
+		valibotValidator := `
+import { object, pipe, string, regex, number } from 'valibot';
+
+const A = object({
+  ID: pipe(string(), regex(/^\d+$/)),
+  Price: pipe(string(), regex(/^-?\d+$/)),
+  Small: number(),
+});
+`
+
+		outputParse, err := CodeParseWithOptions(simpleStruct, Options{Int64: Int64AsString})
+		t.Log(outputParse)
+
+		if err != nil {
+			t.Log("Error is not null")
+			t.Log(err)
+			t.FailNow()
+		}
+
+		if outputParse != valibotValidator {
+			t.FailNow()
+		}
+	})
+}
+
+func TestIntegerChecks(t *testing.T) {
+	simpleStruct := `
+package types
+
+type A struct {
+  A int8
+  B uint16
+  C uint
+  D int
+  E float32
+  F int32 ` + "`json:\",string\"`" + `
+}
+`
+
+	valibotValidator := `
+import { object, pipe, number, integer, minValue, maxValue, string, regex, transform } from 'valibot';
+
+const A = object({
+  A: pipe(number(), integer(), minValue(-128), maxValue(127)),
+  B: pipe(number(), integer(), minValue(0), maxValue(65535)),
+  C: pipe(number(), integer(), minValue(0)),
+  D: pipe(number(), integer()),
+  E: number(),
+  F: pipe(string(), regex(/^-?\d+$/), transform(Number), integer(), minValue(-2147483648), maxValue(2147483647)),
+});
+`
+
+	outputParse, err := CodeParseWithOptions(simpleStruct, Options{IntegerChecks: true})
+	t.Log(outputParse)
+
+	if err != nil {
+		t.Log("Error is not null")
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if outputParse != valibotValidator {
+		t.FailNow()
+	}
+}
+
+func TestInterfaces(t *testing.T) {
+	simpleStruct := `
+package types
+
+type Circle struct {
+  Type string ` + "`json:\"type\"`" + `
+  Radius float64
+}
+
+type Square struct {
+  Type string ` + "`json:\"type\"`" + `
+  Side float64
+}
+
+//gobridge:union type Circle=circle Square=square
+type Shape interface {
+  Area() float64
+}
+
+type Drawing struct {
+  Main Shape
+  Shapes []Shape
+  Metadata interface{}
+  Extra any
+}
+`
+
+	valibotValidator := `
+import { object, string, number, variant, literal, array, unknown } from 'valibot';
+
+const Circle = object({
+  type: string(),
+  Radius: number(),
+});
+
+const Square = object({
+  type: string(),
+  Side: number(),
+});
+
+const Drawing = object({
+  Main: variant("type", [object({ ...Circle.entries, type: literal("circle") }), object({ ...Square.entries, type: literal("square") })]),
+  Shapes: array(variant("type", [object({ ...Circle.entries, type: literal("circle") }), object({ ...Square.entries, type: literal("square") })])),
+  Metadata: unknown(),
+  Extra: unknown(),
+});
+`
+
+	outputParse, err := CodeParse(simpleStruct)
+	t.Log(outputParse)
+
+	if err != nil {
+		t.Log("Error is not null")
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if outputParse != valibotValidator {
+		t.FailNow()
+	}
+}
+
+func TestInterfaceUnknownVariant(t *testing.T) {
+	simpleStruct := `
+package types
+
+//gobridge:union type Circle
+type Shape interface{}
+
+type Drawing struct {
+  Main Shape
+}
+`
+
+	_, err := CodeParse(simpleStruct)
+	if err == nil {
+		t.Log("Expected an error for a variant that doesn't exist")
+		t.FailNow()
+	}
+}
+
+func TestInterfaceUnionOption(t *testing.T) {
+	simpleStruct := `
+package types
+
+type Circle struct {
+  Radius float64
+}
+
+type Shape interface{}
+
+type Drawing struct {
+  Main Shape
+}
+`
+
+	valibotValidator := `
+import { object, number, variant, literal } from 'valibot';
+
+const Circle = object({
+  Radius: number(),
+});
+
+const Drawing = object({
+  Main: variant("type", [object({ ...Circle.entries, type: literal("circle") })]),
+});
+`
+
+	outputParse, err := CodeParseWithOptions(simpleStruct, Options{Unions: map[string]string{"types.Shape": "type Circle=circle"}})
+	t.Log(outputParse)
+
+	if err != nil {
+		t.Log("Error is not null")
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if outputParse != valibotValidator {
+		t.FailNow()
+	}
+}
+
+func TestMarshalers(t *testing.T) {
+	simpleStruct := `
+package types
+
+import "time"
+
+type Money struct {
+  Cents int64
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+  return nil, nil
+}
+
+type Level int
+
+func (l *Level) MarshalText() ([]byte, error) {
+  return nil, nil
+}
+
+type Order struct {
+  Total Money
+  Level Level
+  Created time.Time
+}
+`
+
+	t.Run("Without overrides", func(t *testing.T) {
+		valibotValidator := `
+import { object, unknown, string, any } from 'valibot';
+
+const Order = object({
+  Total: unknown(),
+  Level: string(),
+  Created: any(),
+});
+`
+
+		warnings := make([]string, 0)
+		options := Options{Warn: func(message string) {
+			warnings = append(warnings, message)
+		}}
+
+		outputParse, err := CodeParseWithOptions(simpleStruct, options)
+		t.Log(outputParse)
+		t.Log(warnings)
+
+		if err != nil {
+			t.Log("Error is not null")
+			t.Log(err)
+			t.FailNow()
+		}
+
+		if outputParse != valibotValidator {
+			t.FailNow()
+		}
+
+		if len(warnings) != 2 {
+			t.Log("Expected a warning for Money and Level")
+			t.FailNow()
+		}
+	})
+
+	t.Run("With overrides", func(t *testing.T) {
+		valibotValidator := `
+import { object, pipe, string, decimal, picklist, isoTimestamp } from 'valibot';
+
+const Order = object({
+  Total: pipe(string(), decimal()),
+  Level: picklist(['low', 'high']),
+  Created: pipe(string(), isoTimestamp()),
+});
+`
+
+		options := Options{Overrides: map[string]string{
+			"types.Money": "pipe(string(), decimal())",
+			"types.Level": "picklist(['low', 'high'])",
+			"time.Time":   "pipe(string(), isoTimestamp())",
+		}}
+
+		outputParse, err := CodeParseWithOptions(simpleStruct, options)
+		t.Log(outputParse)
+
+		if err != nil {
+			t.Log("Error is not null")
+			t.Log(err)
+			t.FailNow()
+		}
+
+		if outputParse != valibotValidator {
+			t.FailNow()
+		}
+	})
+
+	t.Run("With javascript in overrides", func(t *testing.T) {
+		valibotValidator := `
+import { object, pipe, string, transform, picklist, isoTimestamp } from 'valibot';
+
+const Order = object({
+  Total: pipe(string(), transform((v) => Number(v))),
+  Level: picklist(Object.keys({ low: 1, high: 2 })),
+  Created: pipe(string(), isoTimestamp()),
+});
+`
+
+		options := Options{Overrides: map[string]string{
+			"types.Money": "pipe(string(), transform((v) => Number(v)))",
+			"types.Level": "picklist(Object.keys({ low: 1, high: 2 }))",
+			"time.Time":   "pipe(string(), isoTimestamp())",
+		}}
+
+		outputParse, err := CodeParseWithOptions(simpleStruct, options)
+		t.Log(outputParse)
+
+		if err != nil {
+			t.Log(err)
+			t.FailNow()
+		}
+
+		if outputParse != valibotValidator {
+			t.FailNow()
+		}
+	})
+}
+func TestPromotedMarshalers(t *testing.T) {
+	simpleStruct := `
+package types
+
+import "time"
+
+type Money struct {
+  Cents int64
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+  return nil, nil
+}
+
+type Price struct {
+  Money
+  Currency string
+}
+
+type Stamp struct {
+  *time.Time
+  Zone string
+}
+
+type Order struct {
+  Total Price
+  Created Stamp
+}
+`
+
+	valibotValidator := `
+import { object, unknown } from 'valibot';
+
+const Order = object({
+  Total: unknown(),
+  Created: unknown(),
+});
+`
+
+	warnings := make([]string, 0)
+	options := Options{Warn: func(message string) {
+		warnings = append(warnings, message)
+	}}
+
+	outputParse, err := CodeParseWithOptions(simpleStruct, options)
+	t.Log(outputParse)
+	t.Log(warnings)
+
+	if err != nil {
+		t.Log("Error is not null")
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if outputParse != valibotValidator {
+		t.FailNow()
+	}
+
+	if len(warnings) != 2 {
+		t.Log("Expected a warning for Price and Stamp")
+		t.FailNow()
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	_, err := CodeParse(`
+package types
+
+type A struct {
+  Name string
+  Inner struct {
+    C chan int
+  }
+}
+`)
+
+	var sourceError SourceError
+	if !errors.As(err, &sourceError) {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	if sourceError.Line != 7 || sourceError.Column != 5 || sourceError.Struct != "A" || sourceError.Field != "C" {
+		t.Log(sourceError)
+		t.FailNow()
+	}
+
+	expected := "7:5: Currently, we don't support *ast.ChanType types. (field C of struct A)"
+	if err.Error() != expected {
+		t.Log(err.Error())
+		t.FailNow()
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	errs := make([]error, 0)
+
+	valibotString, err := CodeParseWithOptions(`
+package types
+
+type A struct {
+  Name string
+  C chan int `+"`json:\"c\"`"+`
+  Inner struct {
+    D func()
+    E int
+  }
+}
+`, Options{Error: func(err error) { errs = append(errs, err) }})
+
+	if err != nil {
+		t.Log(err)
+		t.FailNow()
+	}
+
+	valibotValidator := `
+import { object, string, unknown, number } from 'valibot';
+
+const A = object({
+  Name: string(),
+  c: unknown(),
+  Inner: object({
+    D: unknown(),
+    E: number(),
+  }),
+});
+`
+
+	if valibotString != valibotValidator {
+		t.Log(valibotString)
+		t.FailNow()
+	}
+
+	var unsupported UnsupportedError
+	if len(errs) != 2 || !errors.As(errs[0], &unsupported) {
+		t.Log(errs)
+		t.FailNow()
+	}
+}
@@ -1,60 +1,228 @@
-package main
+package gobridge
 
-import "slices"
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
 
-// Naive solution
+// A field that could end up in a struct, after promoting the
+// fields of its embedded structs.
+type promotedField struct {
+	field StructField
+
+	jsonName string
+	tagged   bool
+
+	/* Position of the field, through every embedded struct it was promoted from */
+	index []int
+}
+
+type embeddedLevel struct {
+	fields []StructField
+	index  []int
+
+	/* Namespaced name of the embedded struct, empty for the top level struct */
+	structName string
+}
+
+// Replaces every embedded struct with its promoted fields.
 //
-// 1. Search through every single field of struct.
-// 2. Recursively search through fields that can contain more fields.
-// 3. Replace the ones that are embedded.
+// Promotion follows encoding/json (see typeFields in encoding/json/encode.go):
 //
-// A better approach would involve keeping some map of where the embedded
-// structs are, instead of having to search for them.
-func rebuildStruct(structs []Struct, processingStruct Struct) Struct {
-	processingStruct.Fields = rebuildStructFields(structs, processingStruct.Fields)
+// 1. Embedded structs are walked breadth first, so shallower fields win.
+// 2. Embedded structs with a json name are not promoted, they are normal fields.
+// 3. Of the fields sharing a name at the same depth, a tagged field wins.
+// 4. Any other fields sharing a name at the same depth are ambiguous, and dropped.
+func promoteStructs(structs []Struct) ([]Struct, error) {
+	//
+	// Embedded structs are looked up in their original form,
+	// so we have to keep a copy that isn't promoted yet.
+	//
+	structMap := make(map[string]Struct)
+	for _, s := range structs {
+		structMap[s.Name] = s
+	}
+
+	promotedStructs := make([]Struct, len(structs))
+
+	for i, s := range structs {
+		fields, err := promoteFields(structMap, s.Fields)
+		if err != nil {
+			return []Struct{}, err
+		}
+
+		s.Fields = fields
+		promotedStructs[i] = s
+	}
+
+	return promotedStructs, nil
+}
 
-	return processingStruct
+func getJsonTagName(tag string) (string, bool) {
+	jsonTag := reflect.StructTag(tag).Get("json")
+	if jsonTag == "-" {
+		return "", false
+	}
+
+	return strings.Split(jsonTag, ",")[0], true
 }
 
-func rebuildStructFields(structs []Struct, fields []StructField) []StructField {
-	processedFields := make([]StructField, 0)
-
-	for _, field := range fields {
-		switch t := field.(type) {
-		case UnknownStructField:
-			processedFields = append(processedFields, t)
-		case BasicStructField:
-			if t.name != EMBEDDED_DEP {
-				processedFields = append(processedFields, t)
-				continue
+func promoteFields(structs map[string]Struct, fields []StructField) ([]StructField, error) {
+	candidates := make([]promotedField, 0)
+
+	current := []embeddedLevel{}
+	next := []embeddedLevel{{fields: fields}}
+
+	count := make(map[string]int)
+	nextCount := make(map[string]int)
+
+	visited := make(map[string]bool)
+
+	for len(next) > 0 {
+		current, next = next, []embeddedLevel{}
+		count, nextCount = nextCount, make(map[string]int)
+
+		for _, level := range current {
+			if level.structName != "" {
+				if visited[level.structName] {
+					continue
+				}
+
+				visited[level.structName] = true
 			}
 
-			embeddedStructIndex := slices.IndexFunc(structs, func(s Struct) bool {
-				return s.Name == t.Type
-			})
+			for i, field := range level.fields {
+				tagName, include := getJsonTagName(field.Tag())
+				if !include {
+					continue
+				}
+
+				index := append(slices.Clone(level.index), i)
+
+				embedded, isEmbedded := field.(EmbeddedStructField)
+				if isEmbedded && tagName == "" {
+					nextCount[embedded.Type]++
+					if nextCount[embedded.Type] > 1 {
+						continue
+					}
+
+					embeddedStruct, exists := structs[embedded.Type]
+					if !exists {
+						return []StructField{}, errors.New("Could not find embedded struct " + embedded.Type)
+					}
+
+					next = append(next, embeddedLevel{fields: embeddedStruct.Fields, index: index, structName: embedded.Type})
+					continue
+				}
+
+				if isEmbedded {
+					field = BasicStructField{FieldName: embedded.FieldName, Type: embedded.Type, StructTag: embedded.StructTag, Doc: embedded.Doc, Position: embedded.Position}
+				}
+
+				jsonName := tagName
+				if jsonName == "" {
+					jsonName = field.Name()
+				}
+
+				candidate := promotedField{field: field, jsonName: jsonName, tagged: tagName != "", index: index}
+				candidates = append(candidates, candidate)
 
-			processedFields = append(processedFields, structs[embeddedStructIndex].Fields...)
-		default:
-			processedFields = append(processedFields, recReplaceEmbeddedStruct(structs, t))
+				//
+				// The same struct was embedded more than once at this depth,
+				// a duplicate is enough for its fields to be annihilated.
+				//
+				if count[level.structName] > 1 {
+					candidates = append(candidates, candidate)
+				}
+			}
 		}
 	}
 
-	return processedFields
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a := candidates[i]
+		b := candidates[j]
+
+		if a.jsonName != b.jsonName {
+			return a.jsonName < b.jsonName
+		}
+
+		if len(a.index) != len(b.index) {
+			return len(a.index) < len(b.index)
+		}
+
+		if a.tagged != b.tagged {
+			return a.tagged
+		}
+
+		return slices.Compare(a.index, b.index) < 0
+	})
+
+	dominantFields := make([]promotedField, 0)
+
+	for i := 0; i < len(candidates); {
+		j := i + 1
+		for j < len(candidates) && candidates[j].jsonName == candidates[i].jsonName {
+			j++
+		}
+
+		sameName := candidates[i:j]
+		i = j
+
+		if len(sameName) > 1 && len(sameName[0].index) == len(sameName[1].index) && sameName[0].tagged == sameName[1].tagged {
+			continue
+		}
+
+		dominantFields = append(dominantFields, sameName[0])
+	}
+
+	sort.Slice(dominantFields, func(i, j int) bool {
+		return slices.Compare(dominantFields[i].index, dominantFields[j].index) < 0
+	})
+
+	processedFields := make([]StructField, 0, len(dominantFields))
+
+	for _, f := range dominantFields {
+		field, err := recReplaceEmbeddedStruct(structs, f.field)
+		if err != nil {
+			return []StructField{}, err
+		}
+
+		processedFields = append(processedFields, field)
+	}
+
+	return processedFields, nil
 }
 
-func recReplaceEmbeddedStruct(structs []Struct, field StructField) StructField {
+func recReplaceEmbeddedStruct(structs map[string]Struct, field StructField) (StructField, error) {
 	switch t := field.(type) {
 	case AnonStructField:
-		return AnonStructField{
-			name: t.name,
-
-			Fields: rebuildStructFields(structs, t.Fields),
+		fields, err := promoteFields(structs, t.Fields)
+		if err != nil {
+			return t, err
 		}
+
+		t.Fields = fields
+		return t, nil
 	case MapStructField:
-		return recReplaceEmbeddedStruct(structs, t.Value)
+		value, err := recReplaceEmbeddedStruct(structs, t.Value)
+		if err != nil {
+			return t, err
+		}
+
+		t.Value = value
+		return t, nil
 	case ArrayStructField:
-		return recReplaceEmbeddedStruct(structs, t.Type)
+		value, err := recReplaceEmbeddedStruct(structs, t.Type)
+		if err != nil {
+			return t, err
+		}
+
+		t.Type = value
+		return t, nil
 	default:
-		panic("unreachable")
+		return t, nil
 	}
 }
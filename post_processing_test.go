@@ -0,0 +1,382 @@
+package gobridge
+
+import "testing"
+
+// Cases mirror the embedded field tests of encoding/json.
+func TestEmbeddedPromotion(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{
+			name: "AmbiguousField",
+			code: `
+package types
+
+type S1 struct {
+  X int
+}
+
+type S2 struct {
+  X int
+}
+
+type S struct {
+  S1
+  S2
+}
+`,
+			expected: `
+import { object, number } from 'valibot';
+
+const S1 = object({
+  X: number(),
+});
+
+const S2 = object({
+  X: number(),
+});
+
+const S = object({
+});
+`,
+		},
+		{
+			name: "DominantField",
+			code: `
+package types
+
+type S1 struct {
+  X int
+}
+
+type S2 struct {
+  X int
+}
+
+type S struct {
+  S1
+  S2
+  X string
+}
+`,
+			expected: `
+import { object, number, string } from 'valibot';
+
+const S1 = object({
+  X: number(),
+});
+
+const S2 = object({
+  X: number(),
+});
+
+const S = object({
+  X: string(),
+});
+`,
+		},
+		{
+			name: "UnexportedEmbeddedInt",
+			code: `
+package types
+
+type myInt int
+
+type S struct {
+  myInt
+  Y string
+}
+`,
+			expected: `
+import { object, string } from 'valibot';
+
+const S = object({
+  Y: string(),
+});
+`,
+		},
+		{
+			name: "ExportedEmbeddedInt",
+			code: `
+package types
+
+type MyInt int
+
+type S struct {
+  MyInt
+}
+`,
+			expected: `
+import { object, number } from 'valibot';
+
+const S = object({
+  MyInt: number(),
+});
+`,
+		},
+		{
+			name: "EmbeddedStructPointer",
+			code: `
+package types
+
+type s1 struct {
+  X int
+}
+
+type S struct {
+  *s1
+  Y string
+}
+`,
+			expected: `
+import { object, number, string } from 'valibot';
+
+const s1 = object({
+  X: number(),
+});
+
+const S = object({
+  Y: string(),
+});
+`,
+		},
+		{
+			name: "EmbeddedStruct",
+			code: `
+package types
+
+type s1 struct {
+  X int
+}
+
+type S struct {
+  s1
+  Y string
+}
+`,
+			expected: `
+import { object, number, string } from 'valibot';
+
+const s1 = object({
+  X: number(),
+});
+
+const S = object({
+  X: number(),
+  Y: string(),
+});
+`,
+		},
+		{
+			name: "EmbeddedInterface",
+			code: `
+package types
+
+type reader interface {
+  Read() string
+}
+
+type S struct {
+  error
+  reader
+  X int
+}
+`,
+			expected: `
+import { object, number } from 'valibot';
+
+const S = object({
+  X: number(),
+});
+`,
+		},
+		{
+			name: "UnexportedFields",
+			code: `
+package types
+
+type S struct {
+  X int
+  y string
+  z string ` + "`json:\"z\"`" + `
+}
+`,
+			expected: `
+import { object, number } from 'valibot';
+
+const S = object({
+  X: number(),
+});
+`,
+		},
+		{
+			name: "DuplicatedFieldDisappears",
+			code: `
+package types
+
+type BugA struct {
+  S string
+}
+
+type BugB struct {
+  BugA
+  S string
+}
+
+type BugX struct {
+  A int
+  BugA
+  BugB
+}
+`,
+			expected: `
+import { object, string, number } from 'valibot';
+
+const BugA = object({
+  S: string(),
+});
+
+const BugB = object({
+  S: string(),
+});
+
+const BugX = object({
+  A: number(),
+});
+`,
+		},
+		{
+			name: "TaggedFieldDominates",
+			code: `
+package types
+
+type BugA struct {
+  S string
+}
+
+type BugD struct {
+  XXX int ` + "`json:\"S\"`" + `
+}
+
+type BugY struct {
+  BugA
+  BugD
+}
+`,
+			expected: `
+import { object, string, number } from 'valibot';
+
+const BugA = object({
+  S: string(),
+});
+
+const BugD = object({
+  S: number(),
+});
+
+const BugY = object({
+  S: number(),
+});
+`,
+		},
+		{
+			name: "EmbeddedWithName",
+			code: `
+package types
+
+type Base struct {
+  ID string
+}
+
+type S struct {
+  Base ` + "`json:\"base\"`" + `
+  Ignored string ` + "`json:\"-\"`" + `
+  Renamed string ` + "`json:\"first-name,omitempty\"`" + `
+}
+`,
+			expected: `
+import { object, string } from 'valibot';
+
+const Base = object({
+  ID: string(),
+});
+
+const S = object({
+  base: Base,
+  "first-name": string(),
+});
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outputParse, err := CodeParse(test.code)
+			t.Log(outputParse)
+
+			if err != nil {
+				t.Log("Error is not null")
+				t.Log(err)
+				t.FailNow()
+			}
+
+			if outputParse != test.expected {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestEmbeddedErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+	}{
+		{
+			name: "MissingEmbeddedStruct",
+			code: `
+package types
+
+type A struct {
+  Missing
+}
+`,
+		},
+		{
+			name: "MissingStruct",
+			code: `
+package types
+
+type A struct {
+  Field Missing
+}
+`,
+		},
+		{
+			name: "RecursiveNamedType",
+			code: `
+package types
+
+type Tree map[string]Tree
+
+type A struct {
+  Field Tree
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := CodeParse(test.code)
+			t.Log(err)
+
+			if err == nil {
+				t.Log("Expected an error")
+				t.FailNow()
+			}
+		})
+	}
+}
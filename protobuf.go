@@ -0,0 +1,333 @@
+package gobridge
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const PROTOBUF_TARGET = "proto"
+
+// Numbers from here are used by the protobuf implementation,
+// so new fields skip over them.
+const (
+	PROTO_RESERVED_START = 19000
+	PROTO_RESERVED_END   = 19999
+)
+
+// The numbers of the fields of every message, which must never change
+// once a message has been used, so they are kept between generations.
+// Fields that were removed keep their numbers, and are reserved.
+//
+// Map: message -> field -> number, where nested messages are `User.Address`
+type FieldNumbers map[string]map[string]int
+
+// The number of a field, and the next free number when it doesn't have one yet.
+func (f FieldNumbers) get(message string, field string) int {
+	numbers, exists := f[message]
+	if !exists {
+		numbers = make(map[string]int)
+		f[message] = numbers
+	}
+
+	number, exists := numbers[field]
+	if exists {
+		return number
+	}
+
+	for _, used := range numbers {
+		number = max(number, used)
+	}
+
+	number++
+	if number >= PROTO_RESERVED_START && number <= PROTO_RESERVED_END {
+		number = PROTO_RESERVED_END + 1
+	}
+
+	numbers[field] = number
+	return number
+}
+
+/*
+ * proto3 messages. Every field gets its number from Options.FieldNumbers,
+ * and the numbers of new fields are added to it.
+ */
+type protobufGenerator struct {
+	options Options
+	nameMap map[string]string
+	numbers FieldNumbers
+
+	// Fields come before their struct,
+	// so the struct is always the next one in the list.
+	structList StructList
+	current    int
+
+	imports []string
+
+	// Messages for anonymous structs and unions,
+	// written in the message of the field that uses them.
+	nestedMessages string
+
+	/* Map: message -> the fields it has now */
+	fieldNames map[string][]string
+}
+
+func newProtobufGenerator(structList StructList, options Options) Generator {
+	numbers := options.FieldNumbers
+	if numbers == nil {
+		numbers = make(FieldNumbers)
+	}
+
+	return &protobufGenerator{
+		options:    options,
+		nameMap:    getNameMap(structList, options),
+		numbers:    numbers,
+		structList: structList,
+		imports:    make([]string, 0),
+		fieldNames: make(map[string][]string),
+	}
+}
+
+func getProtoIndent(indent uint) string {
+	return strings.Repeat("  ", int(indent))
+}
+
+func getProtoComment(doc string, indent uint) string {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return ""
+	}
+
+	comment := ""
+	for _, line := range strings.Split(doc, "\n") {
+		comment += strings.TrimRight(getProtoIndent(indent)+"// "+line, " ") + "\n"
+	}
+
+	return comment
+}
+
+func getProtoScalar(goType string) string {
+	switch goType {
+	case "string", "bool":
+		return goType
+	case "float32":
+		return "float"
+	case "float64":
+		return "double"
+	case "int8", "int16", "int32":
+		return "int32"
+	case "uint8", "uint16", "uint32":
+		return "uint32"
+	case "int", "int64":
+		return "int64"
+	case "uint", "uint64":
+		return "uint64"
+	}
+
+	return ""
+}
+
+func (g *protobufGenerator) importProto(path string) {
+	if !slices.Contains(g.imports, path) {
+		g.imports = append(g.imports, path)
+	}
+}
+
+func (g *protobufGenerator) Header(structList StructList) string {
+	sort.Strings(g.imports)
+
+	header := "syntax = \"proto3\";\n"
+	if len(g.imports) > 0 {
+		header += "\n"
+	}
+
+	for _, path := range g.imports {
+		header += "import " + strconv.Quote(path) + ";\n"
+	}
+
+	return header
+}
+
+// The numbers and names of fields a message used to have.
+func (g *protobufGenerator) getReserved(message string, indent uint) string {
+	numbers := make([]int, 0)
+	names := make([]string, 0)
+
+	for name, number := range g.numbers[message] {
+		if !slices.Contains(g.fieldNames[message], name) {
+			numbers = append(numbers, number)
+			names = append(names, name)
+		}
+	}
+
+	if len(numbers) == 0 {
+		return ""
+	}
+
+	sort.Ints(numbers)
+	sort.Strings(names)
+
+	reservedNumbers := make([]string, 0, len(numbers))
+	for _, number := range numbers {
+		reservedNumbers = append(reservedNumbers, strconv.Itoa(number))
+	}
+
+	reservedNames := make([]string, 0, len(names))
+	for _, name := range names {
+		reservedNames = append(reservedNames, strconv.Quote(name))
+	}
+
+	reserved := getProtoIndent(indent+1) + "reserved " + strings.Join(reservedNumbers, ", ") + ";\n"
+	return reserved + getProtoIndent(indent+1) + "reserved " + strings.Join(reservedNames, ", ") + ";\n"
+}
+
+func (g *protobufGenerator) getMessage(name string, message string, doc string, body []string, indent uint) string {
+	return getProtoComment(doc, indent) +
+		getProtoIndent(indent) + "message " + name + " {\n" +
+		strings.Join(body, "") +
+		g.getReserved(message, indent) +
+		getProtoIndent(indent) + "}\n"
+}
+
+func (g *protobufGenerator) Struct(s Struct, fields []string) string {
+	g.current++
+
+	name := g.nameMap[s.Name]
+	return "\n" + g.getMessage(name, name, s.Doc, fields, 0)
+}
+
+// `type name = number`, with json_name when proto3 wouldn't use the same key as encoding/json.
+func (g *protobufGenerator) getFieldLine(field StructField, typeExpression string, message string, indent uint) string {
+	jsonName, _ := getJsonTagName(field.Tag())
+	if jsonName == "" {
+		jsonName = field.Name()
+	}
+
+	protoName := toSnakeCase(field.Name())
+	g.fieldNames[message] = append(g.fieldNames[message], protoName)
+
+	line := getProtoIndent(indent+1) + typeExpression + " " + protoName + " = " + strconv.Itoa(g.numbers.get(message, protoName))
+	if toCamelCase(protoName) != jsonName {
+		line += " [json_name = " + strconv.Quote(jsonName) + "]"
+	}
+
+	return getProtoComment(getDoc(field), indent+1) + line + ";\n"
+}
+
+func (g *protobufGenerator) Field(field StructField, typeExpression string, indent uint) string {
+	nestedMessages := g.nestedMessages
+	g.nestedMessages = ""
+
+	message := g.nameMap[g.structList[g.current].Name]
+	return nestedMessages + g.getFieldLine(field, typeExpression, message, indent)
+}
+
+func (g *protobufGenerator) TypeExpression(field StructField, indent uint) (string, error) {
+	message := g.nameMap[g.structList[g.current].Name]
+	return g.getType(field, toPascalCase(field.Name()), message, indent)
+}
+
+// Repeated fields and maps can't be in each other in proto.
+func isProtoCollection(typeExpression string) bool {
+	return strings.HasPrefix(typeExpression, "repeated ") || strings.HasPrefix(typeExpression, "map<")
+}
+
+// messageName names the messages of anonymous structs and unions in the field,
+// which are nested in message.
+func (g *protobufGenerator) getType(field StructField, messageName string, message string, indent uint) (string, error) {
+	switch t := field.(type) {
+	case BasicStructField:
+		_, err := getJsType(t.Type)
+		if err == NoJsType {
+			return g.nameMap[t.Type], nil
+		}
+
+		return getProtoScalar(t.Type), nil
+	case UnknownStructField:
+		switch t.Encoding {
+		case MARSHAL_JSON:
+			g.options.warn(t.FullType + " implements json.Marshaler, using google.protobuf.Value as we can't know its schema.")
+		case MARSHAL_TEXT:
+			return "string", nil
+		}
+
+		g.importProto("google/protobuf/struct.proto")
+		return "google.protobuf.Value", nil
+	case ArrayStructField:
+		//
+		// encoding/json writes []byte as base64, like proto3 does with bytes.
+		//
+		if isByteSlice(t) {
+			return "bytes", nil
+		}
+
+		elementType, err := g.getType(t.Type, messageName, message, indent)
+		if err != nil {
+			return "", err
+		}
+
+		if isProtoCollection(elementType) {
+			return "", unsupportedError("%s is a list of lists or maps, which proto can't have", field.Name())
+		}
+
+		return "repeated " + elementType, nil
+	case MapStructField:
+		keyType := getProtoScalar(t.KeyType)
+		if keyType == "" || keyType == "float" || keyType == "double" {
+			return "", unsupportedError("%s has %s keys, proto map keys can only be integers, strings or bools", field.Name(), t.KeyType)
+		}
+
+		value, err := g.getType(t.Value, messageName, message, indent)
+		if err != nil {
+			return "", err
+		}
+
+		if isProtoCollection(value) {
+			return "", unsupportedError("%s is a map of lists or maps, which proto can't have", field.Name())
+		}
+
+		return "map<" + keyType + ", " + value + ">", nil
+	case AnonStructField:
+		nestedMessage := message + "." + messageName
+
+		body := make([]string, 0, len(t.Fields))
+		for _, nestedField := range t.Fields {
+			typeExpression, err := g.getType(nestedField, toPascalCase(nestedField.Name()), nestedMessage, indent+1)
+			if err != nil {
+				return "", err
+			}
+
+			nestedMessages := g.nestedMessages
+			g.nestedMessages = ""
+
+			body = append(body, nestedMessages+g.getFieldLine(nestedField, typeExpression, nestedMessage, indent+1))
+		}
+
+		g.nestedMessages += g.getMessage(messageName, nestedMessage, "", body, indent+1)
+		return messageName, nil
+	case UnionStructField:
+		//
+		// A message with a oneof of the variants,
+		// as proto has nothing like the discriminator.
+		//
+		nestedMessage := message + "." + messageName
+
+		variants := ""
+		for _, variant := range t.Variants {
+			variantField := BasicStructField{FieldName: variant.Value, Type: variant.Type}
+			variants += g.getFieldLine(variantField, g.nameMap[variant.Type], nestedMessage, indent+2)
+		}
+
+		oneof := getProtoIndent(indent+2) + "oneof " + toSnakeCase(t.Discriminator) + " {\n" + variants + getProtoIndent(indent+2) + "}\n"
+
+		g.nestedMessages += g.getMessage(messageName, nestedMessage, "", []string{oneof}, indent+1)
+		return messageName, nil
+	default:
+		return "", unsupportedError("Cannot generate a proto type for " + field.Name())
+	}
+}
+
+func (g *protobufGenerator) Footer(structList StructList) string {
+	return ""
+}
@@ -0,0 +1,186 @@
+package gobridge
+
+import "testing"
+
+func TestProtobuf(t *testing.T) {
+	structs := getCodeStructs(t, modelsCode)
+
+	//
+	// Nickname was removed and Friends was added after it,
+	// so neither of their numbers can be used for the new fields.
+	//
+	numbers := FieldNumbers{
+		"User": {"id": 1, "nickname": 2, "friends": 3},
+	}
+
+	output, err := Generate(PROTOBUF_TARGET, structs, Options{Header: "Generated", FieldNumbers: numbers})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `// Generated
+syntax = "proto3";
+
+import "google/protobuf/struct.proto";
+
+message Dog {
+  bool barks = 1;
+}
+
+message Cat {
+}
+
+message Friend {
+  Friend best = 1 [json_name = "Best"];
+  message Pet {
+    oneof kind {
+      Dog dog = 1;
+      Cat cat = 2;
+    }
+  }
+  Pet pet = 2;
+}
+
+// Somebody using the app.
+message User {
+  uint32 id = 1;
+  string name = 4;
+  // Who they follow.
+  repeated Friend friends = 3;
+  map<int64, double> scores = 5;
+  repeated string from = 6;
+  bytes avatar = 7;
+  google.protobuf.Value extra = 8;
+  message Address {
+    string street = 1;
+  }
+  Address address = 9;
+  reserved 2;
+  reserved "nickname";
+}
+`
+
+	if output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+
+	if numbers["User"]["address"] != 9 || numbers["User.Address"]["street"] != 1 || numbers["Friend.Pet"]["cat"] != 2 || numbers["Friend"]["pet"] != 2 {
+		t.Log(numbers)
+		t.FailNow()
+	}
+
+	//
+	// Generating again with the same numbers gives the same output.
+	//
+	again, err := Generate(PROTOBUF_TARGET, structs, Options{Header: "Generated", FieldNumbers: numbers})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if again != output {
+		t.Log(again)
+		t.FailNow()
+	}
+}
+
+// byte, rune and uintptr are the same scalars as their types,
+// except []byte, which encoding/json writes as base64 like bytes.
+func TestProtobufScalars(t *testing.T) {
+	structs := getCodeStructs(t, `
+package types
+
+type A struct {
+  B     byte
+  R     rune
+  U     uintptr
+  Data  []byte
+  Runes []rune
+  Hash  [4]byte
+}
+`)
+
+	output, err := Generate(PROTOBUF_TARGET, structs, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `syntax = "proto3";
+
+message A {
+  uint32 b = 1 [json_name = "B"];
+  int32 r = 2 [json_name = "R"];
+  uint64 u = 3 [json_name = "U"];
+  bytes data = 4 [json_name = "Data"];
+  repeated int32 runes = 5 [json_name = "Runes"];
+  repeated uint32 hash = 6 [json_name = "Hash"];
+}
+`
+
+	if output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+}
+
+func TestProtobufRemovedField(t *testing.T) {
+	numbers := make(FieldNumbers)
+
+	before := getCodeStructs(t, "package types\n\ntype A struct {\n  ID int\n  Name string\n  Email string\n}\n")
+
+	_, err := Generate(PROTOBUF_TARGET, before, Options{FieldNumbers: numbers})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//
+	// Name is gone and Phone is new, so Phone can't have its number.
+	//
+	after := getCodeStructs(t, "package types\n\ntype A struct {\n  ID int\n  Email string\n  Phone string\n}\n")
+
+	output, err := Generate(PROTOBUF_TARGET, after, Options{FieldNumbers: numbers})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `syntax = "proto3";
+
+message A {
+  int64 id = 1 [json_name = "ID"];
+  string email = 3 [json_name = "Email"];
+  string phone = 4 [json_name = "Phone"];
+  reserved 2;
+  reserved "name";
+}
+`
+
+	if output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+
+	//
+	// Adding Name back gives it its old number.
+	//
+	_, err = Generate(PROTOBUF_TARGET, before, Options{FieldNumbers: numbers})
+	if err != nil || numbers["A"]["name"] != 2 || numbers["A"]["phone"] != 4 {
+		t.Log(numbers, err)
+		t.FailNow()
+	}
+}
+
+func TestProtobufErrors(t *testing.T) {
+	structs := getCodeStructs(t, "package types\n\ntype A struct {\n  B [][]string\n}\n")
+
+	_, err := Generate(PROTOBUF_TARGET, structs, Options{})
+	if err == nil {
+		t.FailNow()
+	}
+
+	structs = getCodeStructs(t, "package types\n\ntype A struct {\n  B map[string][]string\n}\n")
+
+	_, err = Generate(PROTOBUF_TARGET, structs, Options{})
+	if err == nil {
+		t.FailNow()
+	}
+}
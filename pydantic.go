@@ -0,0 +1,386 @@
+package gobridge
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const PYDANTIC_TARGET = "pydantic"
+
+// Field names that would hide the types we use in later annotations,
+// or can't be python names, get an underscore.
+var PYTHON_RESERVED = []string{
+	"int", "float", "str", "bool", "list", "dict", "tuple",
+	"False", "None", "True", "and", "as", "assert", "async", "await", "break", "class", "continue",
+	"def", "del", "elif", "else", "except", "finally", "for", "from", "global", "if", "import",
+	"in", "is", "lambda", "nonlocal", "not", "or", "pass", "raise", "return", "try", "while", "with", "yield",
+}
+
+/*
+ * Pydantic v2 models. Structs come after the structs they use,
+ * except in cycles, where the struct that isn't defined yet is a
+ * forward reference and the models using it are rebuilt at the end.
+ */
+type pydanticGenerator struct {
+	options Options
+	nameMap map[string]string
+
+	/* Namespaced names of the models already written */
+	defined map[string]bool
+
+	typingImports   []string
+	pydanticImports []string
+
+	// Classes for anonymous structs and union variants,
+	// written in the class of the field that uses them.
+	nestedClasses string
+
+	// Nested classes of the current struct with forward references,
+	// relative to it, and "" for the struct itself.
+	pendingRebuilds []string
+
+	/* Every model with a forward reference */
+	rebuilds []string
+}
+
+func newPydanticGenerator(structList StructList, options Options) Generator {
+	return &pydanticGenerator{
+		options:         options,
+		nameMap:         getNameMap(structList, options),
+		defined:         make(map[string]bool),
+		typingImports:   make([]string, 0),
+		pydanticImports: []string{"BaseModel"},
+		pendingRebuilds: make([]string, 0),
+		rebuilds:        make([]string, 0),
+	}
+}
+
+func getPythonIndent(indent uint) string {
+	return strings.Repeat("    ", int(indent))
+}
+
+func getPythonName(fieldName string) string {
+	name := toSnakeCase(fieldName)
+	if slices.Contains(PYTHON_RESERVED, name) {
+		return name + "_"
+	}
+
+	return name
+}
+
+func (g *pydanticGenerator) importTyping(name string) {
+	if !slices.Contains(g.typingImports, name) {
+		g.typingImports = append(g.typingImports, name)
+	}
+}
+
+func (g *pydanticGenerator) importPydantic(name string) {
+	if !slices.Contains(g.pydanticImports, name) {
+		g.pydanticImports = append(g.pydanticImports, name)
+	}
+}
+
+func (g *pydanticGenerator) Header(structList StructList) string {
+	sort.Strings(g.typingImports)
+	sort.Strings(g.pydanticImports)
+
+	header := "\n"
+	if len(g.typingImports) > 0 {
+		header += "from typing import " + strings.Join(g.typingImports, ", ") + "\n\n"
+	}
+
+	return header + "from pydantic import " + strings.Join(g.pydanticImports, ", ") + "\n"
+}
+
+func getDocstring(doc string, indent uint) string {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return ""
+	}
+
+	doc = strings.ReplaceAll(doc, `\`, `\\`)
+	doc = strings.ReplaceAll(doc, `"""`, `\"\"\"`)
+	doc = strings.ReplaceAll(doc, "\n", "\n"+getPythonIndent(indent))
+
+	return getPythonIndent(indent) + `"""` + doc + `"""` + "\n"
+}
+
+// A class, its body is the fields with the classes they need before them.
+func getPythonClass(name string, base string, doc string, body []string, indent uint) string {
+	class := getPythonIndent(indent) + "class " + name + "(" + base + "):\n" + getDocstring(doc, indent+1)
+	class += strings.Join(body, "")
+
+	if len(body) == 0 && doc == "" {
+		class += getPythonIndent(indent+1) + "pass\n"
+	}
+
+	return class
+}
+
+func (g *pydanticGenerator) Struct(s Struct, fields []string) string {
+	name := g.nameMap[s.Name]
+
+	//
+	// Nested classes are rebuilt before the classes they are in.
+	//
+	sort.SliceStable(g.pendingRebuilds, func(i, j int) bool {
+		return strings.Count(g.pendingRebuilds[i], ".") > strings.Count(g.pendingRebuilds[j], ".")
+	})
+
+	for _, nested := range g.pendingRebuilds {
+		rebuild := name
+		if nested != "" {
+			rebuild += "." + nested
+		}
+
+		if !slices.Contains(g.rebuilds, rebuild) {
+			g.rebuilds = append(g.rebuilds, rebuild)
+		}
+	}
+
+	g.pendingRebuilds = g.pendingRebuilds[:0]
+	g.defined[s.Name] = true
+
+	return "\n\n" + getPythonClass(name, "BaseModel", s.Doc, fields, 0)
+}
+
+// `name: Type = Field(alias="json name")`, the alias is left out when it is the same.
+// Fields with omitempty are `Optional[Type] = None`.
+func (g *pydanticGenerator) getFieldLine(field StructField, typeExpression string, indent uint) string {
+	jsonName, _ := getJsonTagName(field.Tag())
+	if jsonName == "" {
+		jsonName = field.Name()
+	}
+
+	pythonName := getPythonName(field.Name())
+
+	fieldArgs := make([]string, 0)
+	if pythonName != jsonName {
+		fieldArgs = append(fieldArgs, "alias="+strconv.Quote(jsonName))
+	}
+
+	if doc := strings.TrimSpace(getDoc(field)); doc != "" {
+		fieldArgs = append(fieldArgs, "description="+strconv.Quote(doc))
+	}
+
+	//
+	// encoding/json leaves out omitempty fields with an empty value,
+	// so they have to have a default.
+	//
+	optional := hasJsonOption(field.Tag(), "omitempty")
+	if optional {
+		g.importTyping("Optional")
+		typeExpression = "Optional[" + typeExpression + "]"
+	}
+
+	line := getPythonIndent(indent+1) + pythonName + ": " + typeExpression
+	if len(fieldArgs) > 0 {
+		if optional {
+			fieldArgs = append([]string{"default=None"}, fieldArgs...)
+		}
+
+		g.importPydantic("Field")
+		line += " = Field(" + strings.Join(fieldArgs, ", ") + ")"
+	} else if optional {
+		line += " = None"
+	}
+
+	return line + "\n"
+}
+
+func (g *pydanticGenerator) Field(field StructField, typeExpression string, indent uint) string {
+	nestedClasses := g.nestedClasses
+	g.nestedClasses = ""
+
+	return nestedClasses + g.getFieldLine(field, typeExpression, indent)
+}
+
+func (g *pydanticGenerator) TypeExpression(field StructField, indent uint) (string, error) {
+	return g.getType(field, toPascalCase(field.Name()), "", indent)
+}
+
+// The class of a struct, which is a forward reference when it isn't defined yet.
+// classPath is the nested class the reference is in, relative to the struct.
+func (g *pydanticGenerator) getStructType(namespacedName string, classPath string) string {
+	if g.defined[namespacedName] {
+		return g.nameMap[namespacedName]
+	}
+
+	if !slices.Contains(g.pendingRebuilds, classPath) {
+		g.pendingRebuilds = append(g.pendingRebuilds, classPath)
+	}
+
+	return strconv.Quote(g.nameMap[namespacedName])
+}
+
+func (g *pydanticGenerator) getBasicType(goType string) string {
+	switch goType {
+	case "string":
+		return "str"
+	case "bool":
+		return "bool"
+	case "float32", "float64":
+		return "float"
+	}
+
+	if !g.options.IntegerChecks {
+		return "int"
+	}
+
+	checks := make([]string, 0)
+
+	min, max, hasRange := getIntegerRange(goType)
+	if hasRange {
+		checks = append(checks, "ge="+strconv.FormatInt(min, 10), "le="+strconv.FormatInt(max, 10))
+	} else if isUnsignedType(goType) {
+		checks = append(checks, "ge=0")
+	}
+
+	if len(checks) == 0 {
+		return "int"
+	}
+
+	g.importTyping("Annotated")
+	g.importPydantic("Field")
+
+	return "Annotated[int, Field(" + strings.Join(checks, ", ") + ")]"
+}
+
+// className names the classes of anonymous structs and union variants in the field.
+func (g *pydanticGenerator) getType(field StructField, className string, classPath string, indent uint) (string, error) {
+	switch t := field.(type) {
+	case BasicStructField:
+		_, err := getJsType(t.Type)
+		if err == NoJsType {
+			return g.getStructType(t.Type, classPath), nil
+		}
+
+		return g.getBasicType(t.Type), nil
+	case UnknownStructField:
+		switch t.Encoding {
+		case MARSHAL_JSON:
+			g.options.warn(t.FullType + " implements json.Marshaler, using Any as we can't know its schema.")
+		case MARSHAL_TEXT:
+			return "str", nil
+		}
+
+		g.importTyping("Any")
+		return "Any", nil
+	case ArrayStructField:
+		//
+		// encoding/json writes []byte as a base64 string.
+		//
+		if isByteSlice(t) {
+			g.importPydantic("Base64Bytes")
+			return "Base64Bytes", nil
+		}
+
+		element, err := g.getType(t.Type, className, classPath, indent)
+		if err != nil {
+			return "", err
+		}
+
+		if t.Fixed && t.Length == 0 {
+			return "tuple[()]", nil
+		}
+
+		if t.Fixed && t.Length <= MAX_TUPLE_LENGTH {
+			return "tuple[" + strings.Repeat(element+", ", t.Length-1) + element + "]", nil
+		}
+
+		if t.Fixed {
+			g.importTyping("Annotated")
+			g.importPydantic("Field")
+
+			length := strconv.Itoa(t.Length)
+			return "Annotated[list[" + element + "], Field(min_length=" + length + ", max_length=" + length + ")]", nil
+		}
+
+		return "list[" + element + "]", nil
+	case MapStructField:
+		value, err := g.getType(t.Value, className, classPath, indent)
+		if err != nil {
+			return "", err
+		}
+
+		//
+		// encoding/json writes integer keys as strings,
+		// which pydantic turns back into integers.
+		//
+		if isIntegerType(t.KeyType) {
+			return "dict[int, " + value + "]", nil
+		}
+
+		return "dict[str, " + value + "]", nil
+	case AnonStructField:
+		nestedPath := className
+		if classPath != "" {
+			nestedPath = classPath + "." + className
+		}
+
+		body := make([]string, 0, len(t.Fields))
+		for _, nestedField := range t.Fields {
+			typeExpression, err := g.getType(nestedField, toPascalCase(nestedField.Name()), nestedPath, indent+1)
+			if err != nil {
+				return "", err
+			}
+
+			body = append(body, g.Field(nestedField, typeExpression, indent+1))
+		}
+
+		g.nestedClasses += getPythonClass(className, "BaseModel", "", body, indent+1) + "\n"
+		return className, nil
+	case UnionStructField:
+		g.importTyping("Literal")
+
+		//
+		// Each variant gets a subclass with the discriminator. encoding/json
+		// doesn't add it, the struct has to have a field for it.
+		//
+		discriminator := UnknownStructField{FieldName: t.Discriminator}
+
+		variants := make([]string, 0, len(t.Variants))
+		for _, variant := range t.Variants {
+			if !g.defined[variant.Type] {
+				return "", unsupportedError("%s in a union is used before it is defined, pydantic can't subclass it in a cycle", getDisplayName(variant.Type))
+			}
+
+			variantName := className + getName(variant.Type)
+			discriminatorLine := g.getFieldLine(discriminator, "Literal["+strconv.Quote(variant.Value)+"]", indent+1)
+
+			g.nestedClasses += getPythonClass(variantName, g.nameMap[variant.Type], "", []string{discriminatorLine}, indent+1) + "\n"
+			variants = append(variants, variantName)
+		}
+
+		if len(variants) == 1 {
+			return variants[0], nil
+		}
+
+		g.importTyping("Annotated")
+		g.importTyping("Union")
+		g.importPydantic("Field")
+
+		return "Annotated[Union[" + strings.Join(variants, ", ") + "], Field(discriminator=" + strconv.Quote(getPythonName(t.Discriminator)) + ")]", nil
+	default:
+		return "", unsupportedError("Cannot generate a pydantic type for " + field.Name())
+	}
+}
+
+func (g *pydanticGenerator) Comment(line string) string {
+	return "# " + line
+}
+
+func (g *pydanticGenerator) Footer(structList StructList) string {
+	if len(g.rebuilds) == 0 {
+		return ""
+	}
+
+	footer := "\n\n"
+	for _, rebuild := range g.rebuilds {
+		footer += rebuild + ".model_rebuild()\n"
+	}
+
+	return footer
+}
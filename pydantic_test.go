@@ -0,0 +1,124 @@
+package gobridge
+
+import "testing"
+
+func TestPydantic(t *testing.T) {
+	structs := getCodeStructs(t, modelsCode)
+
+	output, err := Generate(PYDANTIC_TARGET, structs, Options{IntegerChecks: true, Header: "Generated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# Generated
+
+from typing import Annotated, Any, Literal, Optional, Union
+
+from pydantic import Base64Bytes, BaseModel, Field
+
+
+class Dog(BaseModel):
+    barks: bool
+
+
+class Cat(BaseModel):
+    pass
+
+
+class Friend(BaseModel):
+    best: "Friend" = Field(alias="Best")
+    class PetDog(Dog):
+        kind: Literal["dog"]
+
+    class PetCat(Cat):
+        kind: Literal["cat"]
+
+    pet: Annotated[Union[PetDog, PetCat], Field(discriminator="kind")]
+
+
+class User(BaseModel):
+    """Somebody using the app."""
+    id: Annotated[int, Field(ge=0, le=255)]
+    name: Optional[str] = None
+    friends: list[Friend] = Field(description="Who they follow.")
+    scores: dict[int, float]
+    from_: tuple[str, str] = Field(alias="from")
+    avatar: Base64Bytes
+    extra: Any
+    class Address(BaseModel):
+        street: str
+
+    address: Address
+
+
+Friend.model_rebuild()
+`
+
+	if output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+}
+
+func TestPydanticOmitempty(t *testing.T) {
+	structs := getCodeStructs(t, `
+package types
+
+type A struct {
+  Name  string `+"`json:\"name,omitempty\"`"+`
+  Count int    `+"`json:\"total,omitempty\"`"+`
+  Tags  []string `+"`json:\",omitempty\"`"+`
+}
+`)
+
+	output, err := Generate(PYDANTIC_TARGET, structs, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+from typing import Optional
+
+from pydantic import BaseModel, Field
+
+
+class A(BaseModel):
+    name: Optional[str] = None
+    count: Optional[int] = Field(default=None, alias="total")
+    tags: Optional[list[str]] = Field(default=None, alias="Tags")
+`
+
+	if output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+}
+
+func TestPydanticCycle(t *testing.T) {
+	output, err := Generate(PYDANTIC_TARGET, getCodeStructs(t, cycleCode), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+from pydantic import BaseModel, Field
+
+
+class Tree(BaseModel):
+    root: "Node" = Field(alias="Root")
+
+
+class Node(BaseModel):
+    children: list["Node"] = Field(alias="Children")
+    parent: Tree = Field(alias="Parent")
+
+
+Tree.model_rebuild()
+Node.model_rebuild()
+`
+
+	if output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+}
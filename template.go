@@ -0,0 +1,194 @@
+package gobridge
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// What a template is executed with.
+type templateData struct {
+	/* In the order of OrderStructs, every struct comes after the structs it uses */
+	Structs StructList
+
+	/* Package paths of the structs, sorted */
+	Packages []string
+}
+
+// The kind of a field, as it is written in the IR.
+func getFieldKind(field StructField) string {
+	switch field.(type) {
+	case BasicStructField:
+		return BASIC_FIELD
+	case UnknownStructField:
+		return UNKNOWN_FIELD
+	case ArrayStructField:
+		return ARRAY_FIELD
+	case MapStructField:
+		return MAP_FIELD
+	case AnonStructField:
+		return ANON_FIELD
+	case UnionStructField:
+		return UNION_FIELD
+	default:
+		panic("Switch should be exhaustive")
+	}
+}
+
+/*
+ * Splits a name into its words, from any casing.
+ * UserID -> User ID, HTTPServer -> HTTP Server, user_id -> user id.
+ */
+func splitWords(name string) []string {
+	words := make([]string, 0)
+	runes := []rune(name)
+	start := 0
+
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == ' ' || r == '.' || r == '/' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+
+			start = i + 1
+			continue
+		}
+
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+
+		previous := runes[i-1]
+		isWordStart := unicode.IsLower(previous) || unicode.IsDigit(previous)
+		isAcronymEnd := unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+		if isWordStart || isAcronymEnd {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+func capitalise(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+
+	return string(runes)
+}
+
+func toPascalCase(name string) string {
+	output := ""
+	for _, word := range splitWords(name) {
+		output += capitalise(word)
+	}
+
+	return output
+}
+
+func toCamelCase(name string) string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return ""
+	}
+
+	output := strings.ToLower(words[0])
+	for _, word := range words[1:] {
+		output += capitalise(word)
+	}
+
+	return output
+}
+
+func toSnakeCase(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "_"))
+}
+
+func toKebabCase(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "-"))
+}
+
+// The namespaced names of every struct a struct uses, once each.
+func getStructDependencies(s Struct) []string {
+	dependencies := make([]string, 0)
+	for _, field := range s.Fields {
+		for _, dependency := range recGetDependencies(field) {
+			if !slices.Contains(dependencies, dependency) {
+				dependencies = append(dependencies, dependency)
+			}
+		}
+	}
+
+	return dependencies
+}
+
+func getTemplateFuncs(nameMap map[string]string) template.FuncMap {
+	return template.FuncMap{
+		// The name of a struct in the output, from its namespaced name.
+		// Structs with the same name in different packages get some of their package path.
+		"name": func(namespacedName string) string {
+			return nameMap[namespacedName]
+		},
+		"displayName": getDisplayName,
+		"kind":        getFieldKind,
+		"isStruct": func(goType string) bool {
+			_, err := getJsType(goType)
+			return err == NoJsType
+		},
+		"jsonName": func(field StructField) string {
+			jsonName, _ := getJsonTagName(field.Tag())
+			if jsonName == "" {
+				return field.Name()
+			}
+
+			return jsonName
+		},
+		"hasJsonOption": func(field StructField, option string) bool {
+			return hasJsonOption(field.Tag(), option)
+		},
+		"dependencies": getStructDependencies,
+
+		"pascal": toPascalCase,
+		"camel":  toCamelCase,
+		"snake":  toSnakeCase,
+		"kebab":  toKebabCase,
+		"upper":  strings.ToUpper,
+		"lower":  strings.ToLower,
+		"join":   strings.Join,
+	}
+}
+
+// Renders the structs, in the order of OrderStructs, through a text/template.
+// name is used in errors, such as the path of the template.
+func GenerateTemplate(name string, templateText string, structList StructList, options Options) (string, error) {
+	nameMap := getNameMap(structList, options)
+
+	t, err := template.New(name).Funcs(getTemplateFuncs(nameMap)).Parse(templateText)
+	if err != nil {
+		return "", err
+	}
+
+	packages := make([]string, 0)
+	for _, s := range structList {
+		if !slices.Contains(packages, s.PackagePath) {
+			packages = append(packages, s.PackagePath)
+		}
+	}
+
+	sort.Strings(packages)
+
+	output := strings.Builder{}
+	err = t.Execute(&output, templateData{Structs: structList, Packages: packages})
+	if err != nil {
+		return "", err
+	}
+
+	return output.String(), nil
+}
@@ -0,0 +1,76 @@
+package gobridge
+
+import "testing"
+
+func TestCasing(t *testing.T) {
+	tests := map[string][4]string{
+		"UserID":     {"UserId", "userId", "user_id", "user-id"},
+		"HTTPServer": {"HttpServer", "httpServer", "http_server", "http-server"},
+		"created_at": {"CreatedAt", "createdAt", "created_at", "created-at"},
+		"v2Name":     {"V2Name", "v2Name", "v2_name", "v2-name"},
+	}
+
+	for name, expected := range tests {
+		actual := [4]string{toPascalCase(name), toCamelCase(name), toSnakeCase(name), toKebabCase(name)}
+		if actual != expected {
+			t.Log(name, actual)
+			t.Fail()
+		}
+	}
+}
+
+const markdownTemplate = `{{define "type"}}{{if eq (kind .) "basic"}}{{if isStruct .Type}}[{{name .Type}}](#{{kebab (name .Type)}}){{else}}{{.Type}}{{end}}{{else if eq (kind .) "array"}}list of {{template "type" .Type}}{{else if eq (kind .) "map"}}map of {{template "type" .Value}}{{else}}anything{{end}}{{end -}}
+# {{join .Packages ", "}}
+{{range .Structs}}
+## {{name .Name}}
+{{range .Fields}}
+- {{jsonName .}}: {{template "type" .}}{{if hasJsonOption . "omitempty"}}, optional{{end}}
+{{- end}}
+{{end}}`
+
+func TestGenerateTemplate(t *testing.T) {
+	structs := getCodeStructs(t, `
+package types
+
+type User struct {
+  UserID  int    `+"`json:\"user_id\"`"+`
+  Friends []Friend `+"`json:\",omitempty\"`"+`
+  Scores  map[string]float64
+  Other   interface{}
+}
+
+type Friend struct {
+  Name string
+}
+`)
+
+	output, err := GenerateTemplate("docs.tmpl", markdownTemplate, structs, Options{NameSuffix: "Doc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# types
+
+## FriendDoc
+
+- Name: string
+
+## UserDoc
+
+- user_id: int
+- Friends: list of [FriendDoc](#friend-doc), optional
+- Scores: map of float64
+- Other: anything
+`
+
+	if output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+
+	_, err = GenerateTemplate("broken.tmpl", "{{range .Structs}}", structs, Options{})
+	if err == nil {
+		t.Log("Broken templates should fail")
+		t.FailNow()
+	}
+}
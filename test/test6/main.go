@@ -3,5 +3,5 @@ package main
 import "time"
 
 type Test6 struct {
-	time time.Time
+	Time time.Time `json:"time"`
 }
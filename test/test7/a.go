@@ -3,10 +3,10 @@ package main
 import "github.com/JohnCosta27/go-bridge/test/test7/nested"
 
 type A struct {
-	a struct {
-		b nested.B
-		c struct {
-			d nested.B
-		}
-	}
+	A struct {
+		B nested.B `json:"b"`
+		C struct {
+			D nested.B `json:"d"`
+		} `json:"c"`
+	} `json:"a"`
 }
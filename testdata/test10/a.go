@@ -0,0 +1,15 @@
+package main
+
+import "github.com/JohnCosta27/go-bridge/testdata/test10/nested"
+
+type A struct {
+	nested.Audited
+	Name  string
+	Other nested.Other
+}
+
+type B struct {
+	A
+	Base  nested.Base
+	Extra bool
+}
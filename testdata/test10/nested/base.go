@@ -0,0 +1,14 @@
+package nested
+
+type Base struct {
+	ID string
+}
+
+type Audited struct {
+	*Base
+	CreatedBy string
+}
+
+type Other struct {
+	X int
+}
@@ -0,0 +1,17 @@
+package main
+
+import "github.com/JohnCosta27/go-bridge/testdata/test11/shapes"
+
+type Circle struct {
+	Kind   string `json:"kind"`
+	Radius float64
+}
+
+//gobridge:union kind Circle=circle shapes.Triangle=triangle
+type Shape interface {
+	isShape()
+}
+
+type Drawing struct {
+	Main Shape
+}
@@ -0,0 +1,6 @@
+package shapes
+
+type Triangle struct {
+	Kind string `json:"kind"`
+	Base float64
+}
@@ -0,0 +1,5 @@
+package main
+
+type A struct {
+	B Missing
+}
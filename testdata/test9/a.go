@@ -0,0 +1,8 @@
+package main
+
+import "github.com/JohnCosta27/go-bridge/testdata/test9/nested"
+
+type Lookup struct {
+	ByID   map[nested.ID]string
+	ByName map[nested.Name]int
+}
@@ -0,0 +1,5 @@
+package nested
+
+type ID int64
+
+type Name string
@@ -1,20 +1,29 @@
-package main
+package gobridge
+
+import "slices"
 
 type Node struct {
 	Name    string
 	Visited bool
 	Edges   []*Node
+
+	/* Set while its edges are visited, so an edge back to it is a cycle */
+	visiting bool
 }
 
+// Structs can use each other, so in a cycle a node can come
+// before a node it depends on.
 func dfs(node *Node, list *[]string) *[]string {
-	if node.Visited {
+	if node.Visited || node.visiting {
 		return list
 	}
 
+	node.visiting = true
 	for _, n := range node.Edges {
 		dfs(n, list)
 	}
 
+	node.visiting = false
 	node.Visited = true
 	*list = append(*list, node.Name)
 
@@ -44,3 +53,40 @@ func topologicalSort(nodes []*Node) []string {
 
 	return longest
 }
+
+// Structs that use themselves, through the structs they use. TypeScript can't
+// infer the type of their schemas, which are used in their own declaration.
+func getCycleStructs(structList StructList) map[string]bool {
+	dependencies := make(map[string][]string)
+	for _, s := range structList {
+		for _, field := range s.Fields {
+			dependencies[s.Name] = append(dependencies[s.Name], recGetDependencies(field)...)
+		}
+	}
+
+	inCycle := make(map[string]bool)
+
+	for _, s := range structList {
+		visited := make(map[string]bool)
+		next := slices.Clone(dependencies[s.Name])
+
+		for len(next) > 0 {
+			name := next[len(next)-1]
+			next = next[:len(next)-1]
+
+			if name == s.Name {
+				inCycle[s.Name] = true
+				break
+			}
+
+			if visited[name] {
+				continue
+			}
+
+			visited[name] = true
+			next = append(next, dependencies[name]...)
+		}
+	}
+
+	return inCycle
+}
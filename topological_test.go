@@ -1,4 +1,4 @@
-package main
+package gobridge
 
 import (
 	"slices"
@@ -140,3 +140,25 @@ func TestTopoDisconnected(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestTopoCycle(t *testing.T) {
+	n1 := Node{Name: "A", Edges: make([]*Node, 0)}
+	n2 := Node{Name: "B", Edges: make([]*Node, 0)}
+	n3 := Node{Name: "C", Edges: make([]*Node, 0)}
+
+	n1.Edges = append(n1.Edges, &n2, &n1)
+	n2.Edges = append(n2.Edges, &n1)
+	n3.Edges = append(n3.Edges, &n1)
+
+	correctOrder := []string{"B", "A", "C"}
+
+	nodeSlice := []*Node{&n1, &n2, &n3}
+	testOrder := topologicalSort(nodeSlice)
+
+	t.Log(testOrder)
+
+	if slices.Compare(correctOrder, testOrder) != 0 {
+		t.Log("Slices are not equal")
+		t.FailNow()
+	}
+}
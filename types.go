@@ -1,59 +1,259 @@
-package main
+package gobridge
 
 type StructField interface {
 	Name() string
+
+	/* The raw struct tag of the field, only set on top level fields */
+	Tag() string
+}
+
+// Where a struct or a field was declared.
+// File is empty for code that didn't come from a file.
+type Position struct {
+	File   string
+	Line   int
+	Column int
 }
 
 type BasicStructField struct {
 	/* Type can be golang type or a golang struct type */
 	Type string
 
-	name string
+	FieldName string
+	StructTag string
+
+	Doc      string
+	Position Position
 }
 
+const (
+	MARSHAL_JSON = "json"
+	MARSHAL_TEXT = "text"
+)
+
 type UnknownStructField struct {
 	FullType string
 
-	name string
+	/* MARSHAL_JSON or MARSHAL_TEXT, if the type encodes itself */
+	Encoding string
+
+	FieldName string
+	StructTag string
+
+	Doc      string
+	Position Position
 }
 
 type ArrayStructField struct {
 	Type StructField
 
-	name string
+	// Fixed size arrays have a Length, which can be 0.
+	// Slices are not fixed and have no length.
+	Fixed  bool
+	Length int
+
+	FieldName string
+	StructTag string
+
+	Doc      string
+	Position Position
 }
 
 type MapStructField struct {
+	/* KeyType is the golang basic type the key is encoded from */
 	KeyType string
 	Value   StructField
 
-	name string
+	FieldName string
+	StructTag string
+
+	Doc      string
+	Position Position
 }
 
 type AnonStructField struct {
 	Fields []StructField
 
-	name string
+	FieldName string
+	StructTag string
+
+	Doc      string
+	Position Position
+}
+
+/* FullType of fields that are interfaces, without a known set of implementations */
+const ANY_TYPE = "any"
+
+type UnionVariant struct {
+	/* Value of the discriminator field for this variant */
+	Value string
+
+	/* Namespaced name of the struct implementing the interface */
+	Type string
+}
+
+/*
+ * An interface with a declared set of implementations,
+ * told apart by the Discriminator field.
+ */
+type UnionStructField struct {
+	Discriminator string
+	Variants      []UnionVariant
+
+	FieldName string
+	StructTag string
+
+	Doc      string
+	Position Position
+}
+
+/*
+ * Embedded structs only exist while parsing,
+ * they are replaced by their promoted fields in post processing.
+ */
+type EmbeddedStructField struct {
+	/* The namespaced name of the embedded struct */
+	Type string
+
+	FieldName string
+	StructTag string
+
+	Doc      string
+	Position Position
 }
 
 func (s BasicStructField) Name() string {
-	return s.name
+	return s.FieldName
 }
 
 func (s UnknownStructField) Name() string {
-	return s.name
+	return s.FieldName
 }
 
 func (s ArrayStructField) Name() string {
-	return s.name
+	return s.FieldName
 }
 
 func (s MapStructField) Name() string {
-	return s.name
+	return s.FieldName
 }
 
 func (s AnonStructField) Name() string {
-	return s.name
+	return s.FieldName
+}
+
+func (s UnionStructField) Name() string {
+	return s.FieldName
+}
+
+func (s EmbeddedStructField) Name() string {
+	return s.FieldName
+}
+
+func (s BasicStructField) Tag() string {
+	return s.StructTag
+}
+
+func (s UnknownStructField) Tag() string {
+	return s.StructTag
+}
+
+func (s ArrayStructField) Tag() string {
+	return s.StructTag
+}
+
+func (s MapStructField) Tag() string {
+	return s.StructTag
+}
+
+func (s AnonStructField) Tag() string {
+	return s.StructTag
+}
+
+func (s UnionStructField) Tag() string {
+	return s.StructTag
+}
+
+func (s EmbeddedStructField) Tag() string {
+	return s.StructTag
+}
+
+func withTag(field StructField, tag string) StructField {
+	switch t := field.(type) {
+	case BasicStructField:
+		t.StructTag = tag
+		return t
+	case UnknownStructField:
+		t.StructTag = tag
+		return t
+	case ArrayStructField:
+		t.StructTag = tag
+		return t
+	case MapStructField:
+		t.StructTag = tag
+		return t
+	case AnonStructField:
+		t.StructTag = tag
+		return t
+	case UnionStructField:
+		t.StructTag = tag
+		return t
+	case EmbeddedStructField:
+		t.StructTag = tag
+		return t
+	default:
+		panic("Switch should be exhaustive")
+	}
+}
+
+// The comment above a top level field, and where it is.
+func withSource(field StructField, doc string, position Position) StructField {
+	switch t := field.(type) {
+	case BasicStructField:
+		t.Doc, t.Position = doc, position
+		return t
+	case UnknownStructField:
+		t.Doc, t.Position = doc, position
+		return t
+	case ArrayStructField:
+		t.Doc, t.Position = doc, position
+		return t
+	case MapStructField:
+		t.Doc, t.Position = doc, position
+		return t
+	case AnonStructField:
+		t.Doc, t.Position = doc, position
+		return t
+	case UnionStructField:
+		t.Doc, t.Position = doc, position
+		return t
+	case EmbeddedStructField:
+		t.Doc, t.Position = doc, position
+		return t
+	default:
+		panic("Switch should be exhaustive")
+	}
+}
+
+func getDoc(field StructField) string {
+	switch t := field.(type) {
+	case BasicStructField:
+		return t.Doc
+	case UnknownStructField:
+		return t.Doc
+	case ArrayStructField:
+		return t.Doc
+	case MapStructField:
+		return t.Doc
+	case AnonStructField:
+		return t.Doc
+	case UnionStructField:
+		return t.Doc
+	case EmbeddedStructField:
+		return t.Doc
+	default:
+		panic("Switch should be exhaustive")
+	}
 }
 
 type Struct struct {
@@ -62,6 +262,10 @@ type Struct struct {
 	Name        string
 	PackagePath string
 
+	/* The comment above the struct */
+	Doc      string
+	Position Position
+
 	Fields []StructField
 }
 
@@ -0,0 +1,54 @@
+package gobridge
+
+import "strings"
+
+// The functions valibot exports, as of v1. Overrides are plain javascript,
+// so calls to anything else, like `Number(v)` in a transform, aren't imported.
+var VALIBOT_EXPORTS = map[string]bool{
+	// Schemas
+	"any": true, "array": true, "bigint": true, "blob": true, "boolean": true,
+	"custom": true, "date": true, "enum_": true, "exactOptional": true, "file": true,
+	"function_": true, "instance": true, "intersect": true, "lazy": true, "literal": true,
+	"looseObject": true, "looseTuple": true, "map": true, "nan": true, "never": true,
+	"nonNullable": true, "nonNullish": true, "nonOptional": true, "null_": true, "nullable": true,
+	"nullish": true, "number": true, "object": true, "objectWithRest": true, "optional": true,
+	"picklist": true, "promise": true, "record": true, "set": true, "strictObject": true,
+	"strictTuple": true, "string": true, "symbol": true, "tuple": true, "tupleWithRest": true,
+	"undefined_": true, "undefinedable": true, "union": true, "unknown": true, "variant": true,
+	"void_": true,
+
+	// Actions
+	"args": true, "base64": true, "bic": true, "brand": true, "bytes": true,
+	"check": true, "checkItems": true, "creditCard": true, "cuid2": true, "decimal": true,
+	"description": true, "digits": true, "email": true, "emoji": true, "empty": true,
+	"endsWith": true, "entries": true, "everyItem": true, "excludes": true, "filterItems": true,
+	"findItem": true, "finite": true, "flavor": true, "graphemes": true, "gtValue": true,
+	"hash": true, "hexadecimal": true, "hexColor": true, "imei": true, "includes": true,
+	"integer": true, "ip": true, "ipv4": true, "ipv6": true, "isoDate": true,
+	"isoDateTime": true, "isoTime": true, "isoTimeSecond": true, "isoTimestamp": true, "isoWeek": true,
+	"length": true, "ltValue": true, "mac": true, "mac48": true, "mac64": true,
+	"maxBytes": true, "maxEntries": true, "maxGraphemes": true, "maxLength": true, "maxSize": true,
+	"maxValue": true, "maxWords": true, "metadata": true, "mimeType": true, "minBytes": true,
+	"minEntries": true, "minGraphemes": true, "minLength": true, "minSize": true, "minValue": true,
+	"minWords": true, "multipleOf": true, "nanoid": true, "nonEmpty": true, "normalize": true,
+	"notBytes": true, "notEntries": true, "notGraphemes": true, "notLength": true, "notSize": true,
+	"notValue": true, "notValues": true, "notWords": true, "octal": true, "parseJson": true,
+	"partialCheck": true, "rawCheck": true, "rawTransform": true, "readonly": true, "reduceItems": true,
+	"regex": true, "returns": true, "rfcEmail": true, "safeInteger": true, "size": true,
+	"slug": true, "someItem": true, "sortItems": true, "startsWith": true, "stringifyJson": true,
+	"title": true, "toLowerCase": true, "toMaxValue": true, "toMinValue": true, "toUpperCase": true,
+	"transform": true, "trim": true, "trimEnd": true, "trimStart": true, "ulid": true,
+	"url": true, "uuid": true, "value": true, "values": true, "words": true,
+
+	// Methods
+	"assert": true, "config": true, "fallback": true, "flatten": true, "forward": true,
+	"getDefault": true, "getDefaults": true, "getFallback": true, "getFallbacks": true, "is": true,
+	"keyof": true, "message": true, "omit": true, "parse": true, "parser": true,
+	"partial": true, "pick": true, "pipe": true, "required": true, "safeParse": true,
+	"safeParser": true, "summarize": true, "unwrap": true,
+}
+
+// Most functions have an async version, such as pipeAsync.
+func isValibotExport(name string) bool {
+	return VALIBOT_EXPORTS[name] || VALIBOT_EXPORTS[strings.TrimSuffix(name, "Async")]
+}
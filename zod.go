@@ -0,0 +1,217 @@
+package gobridge
+
+import (
+	"strconv"
+	"strings"
+)
+
+const ZOD_TARGET = "zod"
+
+/*
+ * Zod schemas, with the same names and checks as the valibot ones.
+ * Everything comes from `z`, so there are no imports to keep track of.
+ */
+type zodGenerator struct {
+	options Options
+	nameMap map[string]string
+
+	/* Namespaced names of the schemas already declared */
+	defined map[string]bool
+
+	/* Namespaced names of the structs in a cycle, see getCycleStructs */
+	cycles map[string]bool
+}
+
+func newZodGenerator(structList StructList, options Options) Generator {
+	return &zodGenerator{
+		options: options,
+		nameMap: getNameMap(structList, options),
+		defined: make(map[string]bool),
+		cycles:  getCycleStructs(structList),
+	}
+}
+
+func (g *zodGenerator) Header(structList StructList) string {
+	return "\nimport { z } from 'zod';\n"
+}
+
+func (g *zodGenerator) Struct(s Struct, fields []string) string {
+	g.defined[s.Name] = true
+
+	declaration := g.nameMap[s.Name]
+	if g.cycles[s.Name] {
+		declaration += ": z.ZodType"
+	}
+
+	return "\nconst " + declaration + " = z.object({\n" + strings.Join(fields, "") + "});\n"
+}
+
+func (g *zodGenerator) Field(field StructField, typeExpression string, indent uint) string {
+	return getSpaces(indent+1) + getObjectKey(field) + ": " + typeExpression + ",\n"
+}
+
+func (g *zodGenerator) TypeExpression(field StructField, indent uint) (string, error) {
+	typeExpression, err := g.getType(field, indent)
+	if err != nil || !usesUndefinedStruct(field, g.defined) {
+		return typeExpression, err
+	}
+
+	return "z.lazy(() => " + typeExpression + ")", nil
+}
+
+// The .int() and range checks for a golang integer type.
+func getZodIntegerChecks(goType string) string {
+	if !isIntegerType(goType) {
+		return ""
+	}
+
+	checks := ".int()"
+
+	min, max, hasRange := getIntegerRange(goType)
+	if hasRange {
+		return checks + ".min(" + strconv.FormatInt(min, 10) + ").max(" + strconv.FormatInt(max, 10) + ")"
+	}
+
+	if isUnsignedType(goType) {
+		return checks + ".min(0)"
+	}
+
+	return checks
+}
+
+func (g *zodGenerator) getNumberType(goType string) string {
+	if isInt64Type(goType) && g.options.Int64 == Int64AsBigInt {
+		return "z.bigint()"
+	}
+
+	if isInt64Type(goType) && g.options.Int64 == Int64AsString {
+		return "z.string().regex(" + getNumberRegex(goType) + ")"
+	}
+
+	if !g.options.IntegerChecks {
+		return "z.number()"
+	}
+
+	return "z.number()" + getZodIntegerChecks(goType)
+}
+
+// Fields tagged with `json:",string"` are encoded by encoding/json as a JSON string.
+func (g *zodGenerator) getQuotedType(goType string) string {
+	switch goType {
+	case "string":
+		return "z.string().transform((value): string => JSON.parse(value))"
+	case "bool":
+		return "z.enum(['true', 'false']).transform((value) => value === 'true')"
+	}
+
+	quoted := "z.string().regex(" + getNumberRegex(goType) + ")"
+
+	if isInt64Type(goType) && g.options.Int64 == Int64AsString {
+		return quoted
+	}
+
+	if isInt64Type(goType) && g.options.Int64 == Int64AsBigInt {
+		return quoted + ".transform(BigInt)"
+	}
+
+	if g.options.IntegerChecks && isIntegerType(goType) {
+		return quoted + ".transform(Number).pipe(z.number()" + getZodIntegerChecks(goType) + ")"
+	}
+
+	return quoted + ".transform(Number)"
+}
+
+func (g *zodGenerator) getType(field StructField, indent uint) (string, error) {
+	switch t := field.(type) {
+	case BasicStructField:
+		jsType, err := getJsType(t.Type)
+		if err == NoJsType {
+			return g.nameMap[t.Type], nil
+		}
+
+		if hasJsonOption(t.StructTag, "string") {
+			return g.getQuotedType(t.Type), nil
+		}
+
+		if jsType == "number" {
+			return g.getNumberType(t.Type), nil
+		}
+
+		return "z." + jsType + "()", nil
+	case UnknownStructField:
+		// Overrides are valibot code, which zod can't run.
+		if _, hasOverride := g.options.Overrides[t.FullType]; hasOverride {
+			g.options.warn(t.FullType + " has an override, which is only used by valibot, using z.unknown() instead.")
+			return "z.unknown()", nil
+		}
+
+		switch t.Encoding {
+		case MARSHAL_JSON:
+			g.options.warn(t.FullType + " implements json.Marshaler, using z.unknown() as we can't know its schema.")
+		case MARSHAL_TEXT:
+			return "z.string()", nil
+		}
+
+		return "z.unknown()", nil
+	case UnionStructField:
+		discriminatorKey := getObjectKey(BasicStructField{FieldName: t.Discriminator})
+
+		variants := make([]string, 0, len(t.Variants))
+		for _, variant := range t.Variants {
+			variants = append(variants, g.nameMap[variant.Type]+".extend({ "+discriminatorKey+": z.literal("+strconv.Quote(variant.Value)+") })")
+		}
+
+		return "z.discriminatedUnion(" + strconv.Quote(t.Discriminator) + ", [" + strings.Join(variants, ", ") + "])", nil
+	case ArrayStructField:
+		if isByteSlice(t) {
+			return "z.string().base64()", nil
+		}
+
+		element, err := g.getType(t.Type, indent+1)
+		if err != nil {
+			return "", err
+		}
+
+		if t.Fixed && t.Length <= MAX_TUPLE_LENGTH {
+			return "z.tuple([" + strings.TrimSuffix(strings.Repeat(element+", ", t.Length), ", ") + "])", nil
+		}
+
+		if t.Fixed {
+			return "z.array(" + element + ").length(" + strconv.Itoa(t.Length) + ")", nil
+		}
+
+		return "z.array(" + element + ")", nil
+	case MapStructField:
+		value, err := g.getType(t.Value, indent+1)
+		if err != nil {
+			return "", err
+		}
+
+		//
+		// encoding/json writes integer keys as their decimal string.
+		//
+		if isIntegerType(t.KeyType) {
+			return "z.record(z.string().regex(" + getNumberRegex(t.KeyType) + "), " + value + ")", nil
+		}
+
+		return "z.record(z.string(), " + value + ")", nil
+	case AnonStructField:
+		output := "z.object({\n"
+		for _, nestedField := range t.Fields {
+			typeExpression, err := g.getType(nestedField, indent+1)
+			if err != nil {
+				return "", err
+			}
+
+			output += g.Field(nestedField, typeExpression, indent+1)
+		}
+
+		return output + getSpaces(indent+1) + "})", nil
+	default:
+		return "", unsupportedError("Cannot generate a zod schema for " + field.Name())
+	}
+}
+
+func (g *zodGenerator) Footer(structList StructList) string {
+	return ""
+}
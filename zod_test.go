@@ -0,0 +1,141 @@
+package gobridge
+
+import "testing"
+
+func TestZodUnion(t *testing.T) {
+	output, err := Generate(ZOD_TARGET, getCodeStructs(t, unionCode), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+import { z } from 'zod';
+
+const Circle = z.object({
+  radius: z.number(),
+});
+
+const Square = z.object({
+  side: z.number(),
+});
+
+const Drawing = z.object({
+  main: z.discriminatedUnion("type", [Circle.extend({ type: z.literal("circle") }), Square.extend({ type: z.literal("square") })]),
+  shapes: z.array(z.discriminatedUnion("type", [Circle.extend({ type: z.literal("circle") }), Square.extend({ type: z.literal("square") })])),
+});
+`
+
+	if output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+}
+
+func TestZod(t *testing.T) {
+	structs := getCodeStructs(t, `
+package types
+
+type A struct {
+  Count   uint8              `+"`json:\"count\"`"+`
+  Big     int64              `+"`json:\"big,string\"`"+`
+  Point   [2]float32
+  Hash    [32]byte
+  Data    []byte
+  ByID    map[int]string
+  Extra   any
+  Address struct {
+    Street string
+  }
+}
+`)
+
+	output, err := Generate(ZOD_TARGET, structs, Options{IntegerChecks: true, Int64: Int64AsBigInt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+import { z } from 'zod';
+
+const A = z.object({
+  count: z.number().int().min(0).max(255),
+  big: z.string().regex(/^-?\d+$/).transform(BigInt),
+  Point: z.tuple([z.number(), z.number()]),
+  Hash: z.array(z.number().int().min(0).max(255)).length(32),
+  Data: z.string().base64(),
+  ByID: z.record(z.string().regex(/^-?\d+$/), z.string()),
+  Extra: z.unknown(),
+  Address: z.object({
+    Street: z.string(),
+  }),
+});
+`
+
+	if output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+}
+
+func TestZodCycle(t *testing.T) {
+	output, err := Generate(ZOD_TARGET, getCodeStructs(t, cycleCode), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+import { z } from 'zod';
+
+const Tree: z.ZodType = z.object({
+  Root: z.lazy(() => Node),
+});
+
+const Node: z.ZodType = z.object({
+  Children: z.lazy(() => z.array(Node)),
+  Parent: Tree,
+});
+`
+
+	if output != expected {
+		t.Log(output)
+		t.FailNow()
+	}
+}
+
+func TestZodOverrides(t *testing.T) {
+	structs := getCodeStructs(t, `
+package types
+
+import "time"
+
+type A struct {
+  CreatedAt time.Time
+}
+`)
+
+	warnings := make([]string, 0)
+	options := Options{
+		Overrides: map[string]string{"time.Time": "pipe(string(), isoTimestamp())"},
+		Warn: func(message string) {
+			warnings = append(warnings, message)
+		},
+	}
+
+	output, err := Generate(ZOD_TARGET, structs, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+import { z } from 'zod';
+
+const A = z.object({
+  CreatedAt: z.unknown(),
+});
+`
+
+	if output != expected || len(warnings) != 1 {
+		t.Log(output, warnings)
+		t.FailNow()
+	}
+}